@@ -0,0 +1,499 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"netinfo/display"
+	"netinfo/network"
+	"netinfo/utils"
+)
+
+// Command is a non-interactive subcommand
+type Command struct {
+	Name  string
+	Usage string
+	Desc  string
+	Run   func(args []string) error
+}
+
+// Commands lists the subcommands available from the command line
+var Commands = []Command{
+	{
+		Name:  "interfaces",
+		Usage: "interfaces [--json [--growth]]",
+		Desc:  "Show network interfaces with address classification",
+		Run:   runInterfaces,
+	},
+	{
+		Name:  "interface",
+		Usage: "interface <name> [--json [--growth]]",
+		Desc:  "Everything about one interface: addresses, counters, routes, gateways, DNS, neighbours, connections",
+		Run:   runInterface,
+	},
+	{
+		Name:  "topology",
+		Usage: "topology [--json]",
+		Desc:  "Bridges, bonds and VLANs as a tree with port STP state and slave links",
+		Run:   runTopology,
+	},
+	{
+		Name:  "netns",
+		Usage: "netns [--json]",
+		Desc:  "List named and process-owned network namespaces",
+		Run:   runNetns,
+	},
+	{
+		Name:  "top",
+		Usage: "top [--interval 1s] [--json] [interface...]",
+		Desc:  "Live RX/TX rates per interface; --json streams one JSON object per sample",
+		Run:   runTop,
+	},
+	{
+		Name:  "wifi",
+		Usage: "wifi [--json] [--watch] [--interval 1s] [interface...]",
+		Desc:  "Wireless SSID, channel, signal, noise and bitrate; --watch follows the signal live",
+		Run:   runWifi,
+	},
+	{
+		Name:  "events",
+		Usage: "events [--json] [--types link,addr,route,neigh] [--hook cmd] [--webhook url] [interface...]",
+		Desc:  "Stream link up/down, address, route and neighbour changes as they happen",
+		Run:   runEvents,
+	},
+	{
+		Name:  "ip",
+		Usage: "ip [--json] | ip history | ip watch [options]",
+		Desc:  "Show IP addresses, the public IP change history, or watch for changes",
+		Run:   runIP,
+	},
+	{
+		Name:  "resolve-explain",
+		Usage: "resolve-explain <name>",
+		Desc:  "Explain how a name is resolved (hosts, nsswitch, search domains)",
+		Run:   runResolveExplain,
+	},
+	{
+		Name:  "dns-propagation",
+		Usage: "dns-propagation <name> [type] [resolver...]",
+		Desc:  "Compare a record across its authoritative servers and resolvers",
+		Run:   runDNSPropagation,
+	},
+	{
+		Name:  "calc",
+		Usage: "calc [--split N] [--summarize] <cidr>...",
+		Desc:  "Subnet calculator: details, split into /N, or summarize CIDRs",
+		Run:   runCalc,
+	},
+	{
+		Name:  "source-address",
+		Usage: "source-address <destination>",
+		Desc:  "Explain which local address is used as source (RFC 6724)",
+		Run:   runSourceAddress,
+	},
+	{
+		Name:  "dualstack",
+		Usage: "dualstack [host[:port]]",
+		Desc:  "Check IPv6 readiness and race IPv4/IPv6 connections (Happy Eyeballs)",
+		Run:   runDualStack,
+	},
+	{
+		Name:  "serve-reflector",
+		Usage: "serve-reflector [--listen addr] [--udp addr] [--trust-proxy]",
+		Desc:  "Serve callers their own address over HTTP (text/JSON) and UDP",
+		Run:   runServeReflector,
+	},
+	{
+		Name:  "nat",
+		Usage: "nat [--lifetime]",
+		Desc:  "Detect the NAT type using RFC 5780 STUN tests",
+		Run:   runNAT,
+	},
+}
+
+// runCommand dispatches args to the matching subcommand
+func runCommand(args []string) error {
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return nil
+	}
+	
+	for _, command := range Commands {
+		if command.Name == name {
+			err := command.Run(args[1:])
+			if err != nil {
+				display.PrintError(err.Error())
+			}
+			return err
+		}
+	}
+	
+	printUsage()
+	err := fmt.Errorf("unknown command: %s", name)
+	display.PrintError(err.Error())
+	return err
+}
+
+// printUsage prints the available subcommands
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: netinfo [options] [command] [arguments]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Without a command the interactive menu is started.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Options:")
+	fmt.Fprintf(os.Stderr, "  %-44s %s\n", "--resolve", "Show reverse DNS names for remote addresses, gateways and ping targets")
+	fmt.Fprintf(os.Stderr, "  %-44s %s\n", "--geoip", "Show location and ASN of connection remotes from local GeoIP databases")
+	fmt.Fprintf(os.Stderr, "  %-44s %s\n", "--netns <name|pid>", "Run the command or menu inside a network namespace (Linux, root)")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, command := range Commands {
+		fmt.Fprintf(os.Stderr, "  %-44s %s\n", command.Usage, command.Desc)
+	}
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// runInterfaces handles "netinfo interfaces [--json [--growth]]"
+func runInterfaces(args []string) error {
+	flags := flag.NewFlagSet("interfaces", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print JSON instead of tables")
+	growth := flags.Bool("growth", false, "With --json, sample the counters again to report growing errors and drops")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !*asJSON {
+		return network.ShowNetworkInterfaces()
+	}
+
+	interfaces, err := network.GetNetworkInterfaces()
+	if err != nil {
+		interfaces, err = network.GetNetworkInterfacesDetailed()
+		if err != nil {
+			return err
+		}
+	}
+	network.AttachVethPeers(interfaces)
+	if *growth {
+		network.TrackCounterGrowth(interfaces, utils.CounterSampleInterval)
+	}
+	return printJSON(interfaces)
+}
+
+// runInterface handles "netinfo interface <name> [--json [--growth]]"
+func runInterface(args []string) error {
+	flags := flag.NewFlagSet("interface", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print JSON instead of tables")
+	growth := flags.Bool("growth", false, "With --json, sample the counters again to report growing errors and drops")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		return fmt.Errorf("usage: netinfo interface <name> [--json [--growth]]")
+	}
+	// Flags may also follow the name
+	name := flags.Arg(0)
+	if err := flags.Parse(flags.Args()[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("usage: netinfo interface <name> [--json [--growth]]")
+	}
+	if !*asJSON {
+		return network.ShowInterfaceDetails(name)
+	}
+
+	report, err := network.GetInterfaceReport(name)
+	if err != nil {
+		return err
+	}
+	if *growth {
+		single := []network.InterfaceInfo{*report.Interface}
+		network.TrackCounterGrowth(single, utils.CounterSampleInterval)
+		report.Interface = &single[0]
+	}
+	return printJSON(report)
+}
+
+// runTopology handles "netinfo topology [--json]"
+func runTopology(args []string) error {
+	flags := flag.NewFlagSet("topology", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print JSON instead of a tree")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !*asJSON {
+		return network.ShowInterfaceTopology()
+	}
+
+	topology, err := network.GetInterfaceTopology()
+	if err != nil {
+		return err
+	}
+	return printJSON(topology)
+}
+
+// runNetns handles "netinfo netns [--json]"
+func runNetns(args []string) error {
+	flags := flag.NewFlagSet("netns", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print JSON instead of a table")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !*asJSON {
+		return network.ShowNamespaces()
+	}
+
+	namespaces, err := network.ListNamespaces()
+	if err != nil {
+		return err
+	}
+	return printJSON(namespaces)
+}
+
+// runTop handles "netinfo top [--interval 1s] [--json] [interface...]"
+func runTop(args []string) error {
+	flags := flag.NewFlagSet("top", flag.ContinueOnError)
+	interval := flags.Duration("interval", time.Second, "Sampling interval")
+	asJSON := flags.Bool("json", false, "Stream samples as newline-delimited JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *interval < 100*time.Millisecond {
+		return fmt.Errorf("interval must be at least 100ms")
+	}
+	return network.MonitorBandwidth(*interval, flags.Args(), *asJSON)
+}
+
+// runWifi handles "netinfo wifi [--json] [--watch] [--interval 1s] [interface...]"
+func runWifi(args []string) error {
+	flags := flag.NewFlagSet("wifi", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print JSON; with --watch, stream one JSON object per reading")
+	watch := flags.Bool("watch", false, "Follow the signal strength until interrupted")
+	interval := flags.Duration("interval", time.Second, "Reading interval for --watch")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *watch {
+		if *interval < 100*time.Millisecond {
+			return fmt.Errorf("interval must be at least 100ms")
+		}
+		return network.WatchWirelessSignal(*interval, flags.Args(), *asJSON)
+	}
+
+	if !*asJSON {
+		return network.ShowWirelessDetails(flags.Args())
+	}
+
+	details, err := network.GetWirelessDetails(flags.Args())
+	if err != nil {
+		return err
+	}
+	return printJSON(details)
+}
+
+// runEvents handles "netinfo events [--json] [--types list] [--hook cmd] [--webhook url] [interface...]"
+func runEvents(args []string) error {
+	flags := flag.NewFlagSet("events", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Stream one JSON object per event")
+	types := flags.String("types", "", "Comma-separated event types to show: "+strings.Join(network.EventCategories, ","))
+	hook := flags.String("hook", "", "Shell command run for every event")
+	webhook := flags.String("webhook", "", "URL that receives every event as a JSON POST")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	opts := network.EventOptions{
+		Interfaces: flags.Args(),
+		Stream:     *asJSON,
+		Hook:       *hook,
+		Webhook:    *webhook,
+	}
+	if *types != "" {
+		opts.Types = strings.Split(*types, ",")
+	}
+	return network.MonitorEvents(opts)
+}
+
+// runIP handles "netinfo ip [--json]", "netinfo ip history" and "netinfo ip watch"
+func runIP(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "history":
+			return network.ShowPublicIPHistory()
+		case "watch":
+			return runIPWatch(args[1:])
+		}
+	}
+
+	flags := flag.NewFlagSet("ip", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print JSON instead of tables")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !*asJSON {
+		return network.ShowIPInformation()
+	}
+
+	report, err := network.GetIPReport()
+	if err != nil {
+		return err
+	}
+	return printJSON(report)
+}
+
+// runIPWatch handles "netinfo ip watch [--interval d] [--hook cmd] [--webhook url]"
+func runIPWatch(args []string) error {
+	cfg := utils.GetConfig()
+
+	flags := flag.NewFlagSet("ip watch", flag.ContinueOnError)
+	interval := flags.String("interval", cfg.PublicIPWatchInterval, "Polling interval, e.g. 30s or 5m")
+	hook := flags.String("hook", cfg.PublicIPHookCommand, "Shell command run when the address changes")
+	webhook := flags.String("webhook", cfg.PublicIPWebhook, "URL that receives a JSON POST when the address changes")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	every, err := time.ParseDuration(*interval)
+	if err != nil || every < 10*time.Second {
+		return fmt.Errorf("invalid interval %q (minimum 10s)", *interval)
+	}
+
+	return network.WatchPublicIP(every, *hook, *webhook)
+}
+
+// runResolveExplain handles "netinfo resolve-explain <name>"
+func runResolveExplain(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: netinfo resolve-explain <name>")
+	}
+	return network.ShowResolveExplain(args[0])
+}
+
+// runDNSPropagation handles "netinfo dns-propagation <name> [type] [resolver...]"
+func runDNSPropagation(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: netinfo dns-propagation <name> [type] [resolver...]")
+	}
+
+	typeName := "A"
+	if len(args) > 1 {
+		typeName = args[1]
+	}
+	qtype, err := network.ParseRecordType(typeName)
+	if err != nil {
+		return err
+	}
+
+	resolvers := utils.GetConfig().PropagationResolvers
+	if len(args) > 2 {
+		resolvers = args[2:]
+	}
+
+	return network.ShowPropagation(args[0], qtype, resolvers)
+}
+
+// runCalc handles "netinfo calc [--split N] [--summarize] <cidr>..."
+func runCalc(args []string) error {
+	flags := flag.NewFlagSet("calc", flag.ContinueOnError)
+	split := flags.String("split", "", "Split each CIDR into subnets of this prefix length (e.g. 26 or /26)")
+	summarize := flags.Bool("summarize", false, "Merge the CIDRs into the smallest set of blocks")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: netinfo calc [--split N] [--summarize] <cidr>...")
+	}
+
+	if *summarize {
+		summary, err := network.SummarizeCIDRs(flags.Args())
+		if err != nil {
+			return err
+		}
+		display.PrintList(summary, fmt.Sprintf("Summary of %d CIDRs", flags.NArg()))
+		return nil
+	}
+
+	for _, cidr := range flags.Args() {
+		if err := network.ShowSubnet(cidr); err != nil {
+			return err
+		}
+		if *split == "" {
+			continue
+		}
+
+		bits, err := strconv.Atoi(strings.TrimPrefix(*split, "/"))
+		if err != nil {
+			return fmt.Errorf("invalid prefix length: %s", *split)
+		}
+		subnets, err := network.SplitSubnet(cidr, bits)
+		if err != nil {
+			return err
+		}
+		display.PrintList(subnets, fmt.Sprintf("%s split into %d subnets", cidr, len(subnets)))
+	}
+	return nil
+}
+
+// runSourceAddress handles "netinfo source-address <destination>"
+func runSourceAddress(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: netinfo source-address <destination>")
+	}
+	return network.ShowSourceSelection(args[0])
+}
+
+// runDualStack handles "netinfo dualstack [host[:port]]"
+func runDualStack(args []string) error {
+	target := utils.GetConfig().DualStackTarget
+	if len(args) > 0 {
+		target = args[0]
+	}
+
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		host, port = target, "443"
+	}
+	return network.ShowDualStack(host, port)
+}
+
+// runServeReflector handles "netinfo serve-reflector [--listen addr] [--udp addr] [--trust-proxy]"
+func runServeReflector(args []string) error {
+	flags := flag.NewFlagSet("serve-reflector", flag.ContinueOnError)
+	listen := flags.String("listen", ":8080", "HTTP listen address")
+	udp := flags.String("udp", "", "UDP listen address, e.g. :8053 (disabled when empty)")
+	trustProxy := flags.Bool("trust-proxy", false, "Report X-Forwarded-For / X-Real-IP instead of the socket peer")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return network.ServeReflector(network.ReflectorConfig{
+		HTTPAddr:   *listen,
+		UDPAddr:    *udp,
+		TrustProxy: *trustProxy,
+	})
+}
+
+// runNAT handles "netinfo nat [--lifetime]"
+func runNAT(args []string) error {
+	flags := flag.NewFlagSet("nat", flag.ContinueOnError)
+	lifetime := flags.Bool("lifetime", false, "Measure how long an idle UDP mapping survives")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	return network.ShowNATType(*lifetime)
+}
@@ -1,16 +1,61 @@
 package cmd
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"netinfo/display"
 	"netinfo/network"
 	"netinfo/utils"
 )
 
+// globalArgs holds the global options to pass to netinfo re-executed in a namespace
+var globalArgs []string
+
 // Execute is the entrypoint invoked by main.
+// With command-line arguments a single command is run, otherwise the interactive menu starts.
 func Execute() {
+	flags := flag.NewFlagSet("netinfo", flag.ExitOnError)
+	flags.Usage = printUsage
+	resolve := flags.Bool("resolve", false, "show reverse DNS names for addresses")
+	geoip := flags.Bool("geoip", false, "show GeoIP location and ASN for connection remotes")
+	netns := flags.String("netns", "", "run inside the network namespace with this name, or pid:PID for a process's")
+	flags.Parse(os.Args[1:])
+	
+	network.SetResolveNames(*resolve)
+	network.SetGeoIPConnections(*geoip)
+	
+	// Options handed on when netinfo is started again inside a namespace
+	if *resolve {
+		globalArgs = append(globalArgs, "--resolve")
+	}
+	if *geoip {
+		globalArgs = append(globalArgs, "--geoip")
+	}
+	
+	// Start over inside the namespace, which then runs the command or menu as usual
+	if *netns != "" && *netns != utils.CurrentNetns() {
+		code, err := network.RunInNamespace(*netns, append(globalArgs, flags.Args()...))
+		if err != nil {
+			display.PrintError(err.Error())
+		}
+		os.Exit(code)
+	}
+	
+	if flags.NArg() > 0 {
+		if err := runCommand(flags.Args()); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+	
+	runMenu()
+}
+
+// runMenu runs the interactive menu loop until the user exits
+func runMenu() {
 mainLoop:
 	for {
 		// Show header
@@ -36,6 +81,23 @@ mainLoop:
 			}
 			display.PauseForUser("")
 			
+		case "interface":
+			display.ClearScreen()
+			display.ShowHeader()
+			if err := pickInterface(); err != nil {
+				display.PrintError(fmt.Sprintf("Failed to show interface details: %v", err))
+			}
+			display.PauseForUser("")
+			
+		case "topology":
+			display.ClearScreen()
+			display.ShowHeader()
+			err := network.ShowInterfaceTopology()
+			if err != nil {
+				display.PrintError(fmt.Sprintf("Failed to show interface topology: %v", err))
+			}
+			display.PauseForUser("")
+			
 		case "ip":
 			display.ClearScreen()
 			display.ShowHeader()
@@ -45,15 +107,100 @@ mainLoop:
 			}
 			display.PauseForUser("")
 			
-		case "dns":
+		case "subnets":
+			display.ClearScreen()
+			display.ShowHeader()
+			err := network.ShowSubnetInformation()
+			if err != nil {
+				display.PrintError(fmt.Sprintf("Failed to show subnets: %v", err))
+			}
+			display.PauseForUser("")
+			
+		case "source":
 			display.ClearScreen()
 			display.ShowHeader()
-			err := network.ShowDNSInformation()
+			target, err := display.ShowInput("Destination address or host name", "2001:4860:4860::8888")
+			if err == nil {
+				err = network.ShowSourceSelection(target)
+			}
 			if err != nil {
-				display.PrintError(fmt.Sprintf("Failed to show DNS information: %v", err))
+				display.PrintError(fmt.Sprintf("Failed to explain source selection: %v", err))
 			}
 			display.PauseForUser("")
 			
+		case "nat":
+			display.ClearScreen()
+			display.ShowHeader()
+			answer, err := display.ShowInput("Measure mapping lifetime? Takes up to four minutes (y/n)", "n")
+			if err == nil {
+				err = network.ShowNATType(strings.HasPrefix(strings.ToLower(answer), "y"))
+			}
+			if err != nil {
+				display.PrintError(fmt.Sprintf("Failed to detect NAT type: %v", err))
+			}
+			display.PauseForUser("")
+			
+		case "dns":
+			for {
+				display.ClearScreen()
+				display.ShowHeader()
+				
+				choice, err := display.ShowDNSMenu()
+				if err != nil {
+					display.PrintError(fmt.Sprintf("Menu error: %v", err))
+					display.PauseForUser("")
+					continue
+				}
+				
+				switch choice {
+				case "servers":
+					display.ClearScreen()
+					display.ShowHeader()
+					err := network.ShowDNSInformation()
+					if err != nil {
+						display.PrintError(fmt.Sprintf("Failed to show DNS information: %v", err))
+					}
+					display.PauseForUser("")
+					
+				case "dnssec":
+					display.ClearScreen()
+					display.ShowHeader()
+					err := network.ShowDNSSECChain()
+					if err != nil {
+						display.PrintError(fmt.Sprintf("Failed to validate DNSSEC chain: %v", err))
+					}
+					display.PauseForUser("")
+					
+				case "resolve":
+					display.ClearScreen()
+					display.ShowHeader()
+					name, err := display.ShowInput("Enter name to resolve", "localhost")
+					if err == nil {
+						err = network.ShowResolveExplain(name)
+					}
+					if err != nil {
+						display.PrintError(fmt.Sprintf("Failed to explain resolution: %v", err))
+					}
+					display.PauseForUser("")
+					
+				case "propagation":
+					display.ClearScreen()
+					display.ShowHeader()
+					err := network.ShowPropagationTest()
+					if err != nil {
+						display.PrintError(fmt.Sprintf("Failed to check propagation: %v", err))
+					}
+					display.PauseForUser("")
+					
+				case "back":
+					goto mainLoop
+					
+				default:
+					display.PrintWarning("Invalid choice. Please try again.")
+					display.PauseForUser("")
+				}
+			}
+			
 		case "gateway":
 			display.ClearScreen()
 			display.ShowHeader()
@@ -170,6 +317,14 @@ mainLoop:
 				}
 			}
 			
+		case "netns":
+			display.ClearScreen()
+			display.ShowHeader()
+			if err := switchNamespace(); err != nil {
+				display.PrintError(fmt.Sprintf("Failed to switch namespace: %v", err))
+				display.PauseForUser("")
+			}
+			
 		case "exit":
 			display.ClearScreen()
 			display.ShowGoodbye()
@@ -182,4 +337,77 @@ mainLoop:
 	}
 }
 
+// pickInterface lets the user choose an interface and shows everything about it
+func pickInterface() error {
+	names, err := network.InterfaceNames()
+	if err != nil {
+		return err
+	}
+	
+	var items []display.MenuItem
+	for _, name := range names {
+		items = append(items, display.MenuItem{Label: name, Value: name, Desc: "Show details of " + name})
+	}
+	items = append(items, display.MenuItem{Label: "Back to Main Menu", Value: "back", Desc: "Return to main menu"})
+	
+	name, err := display.ShowMenu(&display.MenuConfig{
+		Label: "Select interface",
+		Items: items,
+		Size:  10,
+	})
+	if err != nil || name == "back" {
+		return err
+	}
+	
+	display.ClearScreen()
+	display.ShowHeader()
+	return network.ShowInterfaceDetails(name)
+}
 
+// switchNamespace lets the user pick a network namespace and runs the menu inside it.
+// Leaving that menu returns here.
+func switchNamespace() error {
+	if err := network.ShowNamespaces(); err != nil {
+		return err
+	}
+	namespaces, err := network.ListNamespaces()
+	if err != nil {
+		return err
+	}
+	
+	var items []display.MenuItem
+	for _, ns := range namespaces {
+		label := ns.Name
+		if label == "" {
+			label = fmt.Sprintf("PID %d (%s)", ns.PID, ns.Process)
+		}
+		if ns.Current {
+			label += " (current)"
+		}
+		items = append(items, display.MenuItem{
+			Label: label,
+			Value: ns.Target(),
+			Desc:  fmt.Sprintf("%s, %d processes", ns.ID, ns.Processes),
+		})
+	}
+	items = append(items, display.MenuItem{Label: "Back to Main Menu", Value: "back", Desc: "Return to main menu"})
+	
+	target, err := display.ShowMenu(&display.MenuConfig{
+		Label: "Select network namespace",
+		Items: items,
+		Size:  10,
+	})
+	if err != nil || target == "back" {
+		return err
+	}
+	for _, ns := range namespaces {
+		if ns.Target() == target && ns.Current {
+			display.PrintInfo("Already in this namespace")
+			display.PauseForUser("")
+			return nil
+		}
+	}
+	
+	_, err = network.RunInNamespace(target, globalArgs)
+	return err
+}
@@ -1,6 +1,8 @@
 package display
 
 import (
+	"io"
+
 	"github.com/fatih/color"
 )
 
@@ -74,4 +76,14 @@ func PrintError(msg string) {
 // PrintInfo prints an info message
 func PrintInfo(msg string) {
 	color.New(color.FgBlue, color.Bold).Printf("ℹ %s\n", msg)
+}
+
+// FprintWarning prints a warning message to w, e.g. stderr next to a JSON stream
+func FprintWarning(w io.Writer, msg string) {
+	color.New(color.FgYellow, color.Bold).Fprintf(w, "⚠ %s\n", msg)
+}
+
+// FprintError prints an error message to w
+func FprintError(w io.Writer, msg string) {
+	color.New(color.FgRed, color.Bold).Fprintf(w, "✗ %s\n", msg)
 }
\ No newline at end of file
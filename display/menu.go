@@ -3,6 +3,8 @@ package display
 import (
 	"fmt"
 
+	"netinfo/utils"
+
 	"github.com/manifoldco/promptui"
 )
 
@@ -28,15 +30,40 @@ var MainMenuItems = []MenuItem{
 		Value: "interfaces",
 		Desc:  "Show all network interfaces (IP, MAC, MTU, status)",
 	},
+	{
+		Label: "Interface Details",
+		Value: "interface",
+		Desc:  "Pick one interface and show its addresses, counters, routes, DNS, neighbours and connections",
+	},
+	{
+		Label: "Interface Topology",
+		Value: "topology",
+		Desc:  "Show bridges, bonds and VLANs as a tree with their ports and slaves",
+	},
 	{
 		Label: "IP Information",
 		Value: "ip",
 		Desc:  "Show local and public IP addresses",
 	},
 	{
-		Label: "DNS Servers",
+		Label: "Subnets",
+		Value: "subnets",
+		Desc:  "Show network, broadcast and host range of every local address",
+	},
+	{
+		Label: "Source Address",
+		Value: "source",
+		Desc:  "Explain which local address is used to reach a destination (RFC 6724)",
+	},
+	{
+		Label: "NAT Type",
+		Value: "nat",
+		Desc:  "Detect NAT mapping/filtering behaviour and carrier-grade NAT",
+	},
+	{
+		Label: "DNS",
 		Value: "dns",
-		Desc:  "Show configured DNS servers",
+		Desc:  "Show DNS servers and run DNS diagnostics",
 	},
 	{
 		Label: "Default Gateway",
@@ -58,6 +85,11 @@ var MainMenuItems = []MenuItem{
 		Value: "ping",
 		Desc:  "Test connectivity to a host",
 	},
+	{
+		Label: "Network Namespace",
+		Value: "netns",
+		Desc:  "List network namespaces and run the menu inside one",
+	},
 	{
 		Label: "Exit",
 		Value: "exit",
@@ -88,6 +120,34 @@ var ConnectionsMenuItems = []MenuItem{
 	},
 }
 
+var DNSMenuItems = []MenuItem{
+	{
+		Label: "DNS Servers",
+		Value: "servers",
+		Desc:  "Show configured DNS servers and their DNSSEC validation",
+	},
+	{
+		Label: "DNSSEC Chain of Trust",
+		Value: "dnssec",
+		Desc:  "Validate the DNSSEC chain of trust for a name locally",
+	},
+	{
+		Label: "Resolution Path",
+		Value: "resolve",
+		Desc:  "Explain how a name resolves (hosts, nsswitch, search domains)",
+	},
+	{
+		Label: "Propagation Check",
+		Value: "propagation",
+		Desc:  "Compare a record across authoritative servers and public resolvers",
+	},
+	{
+		Label: "Back to Main Menu",
+		Value: "back",
+		Desc:  "Return to main menu",
+	},
+}
+
 var PingMenuItems = []MenuItem{
 	{
 		Label: "Single Host Ping",
@@ -116,7 +176,7 @@ func ShowMainMenu() (string, error) {
 	config := &MenuConfig{
 		Label:    "Select an option",
 		Items:    MainMenuItems,
-		Size:     8,
+		Size:     len(MainMenuItems),
 		Selected: "",
 	}
 	
@@ -135,6 +195,18 @@ func ShowConnectionsMenu() (string, error) {
 	return ShowMenu(config)
 }
 
+// ShowDNSMenu displays the DNS submenu
+func ShowDNSMenu() (string, error) {
+	config := &MenuConfig{
+		Label:    "Select DNS view",
+		Items:    DNSMenuItems,
+		Size:     len(DNSMenuItems),
+		Selected: "",
+	}
+	
+	return ShowMenu(config)
+}
+
 // ShowPingMenu displays the ping submenu
 func ShowPingMenu() (string, error) {
 	config := &MenuConfig{
@@ -222,6 +294,9 @@ func ShowHeader() {
 	PrintBanner()
 	fmt.Println()
 	PrintInfo("Welcome to NetInfo - Network Information & Diagnostics Tool")
+	if ns := utils.CurrentNetns(); ns != "" {
+		PrintWarning(fmt.Sprintf("Network namespace: %s", ns))
+	}
 	PrintSeparator()
 }
 
@@ -0,0 +1,297 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+// Address flags as iproute2 names them
+const (
+	AddrFlagSecondary     = "secondary"
+	AddrFlagTemporary     = "temporary"
+	AddrFlagDeprecated    = "deprecated"
+	AddrFlagTentative     = "tentative" // duplicate address detection still running
+	AddrFlagDADFailed     = "dadfailed" // another host already uses the address
+	AddrFlagDynamic       = "dynamic"   // from SLAAC or DHCP, expires
+	AddrFlagNoPrefixRoute = "noprefixroute"
+	AddrFlagMngTmpAddr    = "mngtmpaddr" // template for temporary addresses
+	AddrFlagOptimistic    = "optimistic"
+	AddrFlagNoDAD         = "nodad"
+)
+
+var addrFlagNames = []string{
+	AddrFlagSecondary, AddrFlagTemporary, AddrFlagDeprecated, AddrFlagTentative, AddrFlagDADFailed,
+	AddrFlagDynamic, AddrFlagNoPrefixRoute, AddrFlagMngTmpAddr, AddrFlagOptimistic, AddrFlagNoDAD,
+}
+
+// INFINITY_LIFE_TIME in the kernel: the address never expires
+const lifetimeInfinity = 4294967295
+
+// LifetimeForever marks an address lifetime that never runs out
+const LifetimeForever = -1
+
+// AddressDetail is one interface address with everything the kernel knows about it
+type AddressDetail struct {
+	Interface string   `json:"interface"`
+	Address   string   `json:"address"`
+	Family    string   `json:"family"`
+	PrefixLen int      `json:"prefix_len"`
+	Scope     string   `json:"scope"` // global, site, link or host
+	Broadcast string   `json:"broadcast,omitempty"`
+	Label     string   `json:"label,omitempty"` // IPv4 alias label, e.g. eth0:1
+	Flags     []string `json:"flags,omitempty"`
+
+	// Seconds left, LifetimeForever for static addresses; nil when the platform does not say
+	ValidLifetime     *int64 `json:"valid_lifetime,omitempty"`
+	PreferredLifetime *int64 `json:"preferred_lifetime,omitempty"`
+}
+
+// CIDR returns the address with its prefix length
+func (a AddressDetail) CIDR() string {
+	return fmt.Sprintf("%s/%d", a.Address, a.PrefixLen)
+}
+
+// HasFlag reports whether the address carries flag
+func (a AddressDetail) HasFlag(flag string) bool {
+	for _, f := range a.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// Usable reports whether the address can be used as a source for new connections
+func (a AddressDetail) Usable() bool {
+	return !a.HasFlag(AddrFlagTentative) && !a.HasFlag(AddrFlagDADFailed) && !a.HasFlag(AddrFlagDeprecated)
+}
+
+// GetAddressDetails returns the addresses of every interface by interface name.
+// On Linux they come from "ip -json addr show", elsewhere from the Go standard library.
+func GetAddressDetails() (map[string][]AddressDetail, error) {
+	if utils.IsLinux() {
+		if details, err := readIPAddrDetails(); err == nil {
+			return details, nil
+		}
+	}
+	return readNetAddrDetails()
+}
+
+// readIPAddrDetails parses "ip -json addr show"
+func readIPAddrDetails() (map[string][]AddressDetail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), utils.LinuxCommandTimeout)
+	defer cancel()
+
+	output, err := utils.CommandWithTimeout(ctx, 5*time.Second, "ip", "-json", "addr", "show")
+	if err != nil {
+		return nil, err
+	}
+
+	var links []struct {
+		IfName   string                   `json:"ifname"`
+		AddrInfo []map[string]interface{} `json:"addr_info"`
+	}
+	if err := json.Unmarshal(output, &links); err != nil {
+		return nil, utils.WrapError(err, "Failed to parse ip addr output", utils.ErrorTypeParse)
+	}
+
+	details := make(map[string][]AddressDetail)
+	for _, link := range links {
+		for _, info := range link.AddrInfo {
+			local, _ := info["local"].(string)
+			if local == "" {
+				continue
+			}
+			prefixLen, _ := info["prefixlen"].(float64)
+			scope, _ := info["scope"].(string)
+			broadcast, _ := info["broadcast"].(string)
+			label, _ := info["label"].(string)
+
+			detail := AddressDetail{
+				Interface: link.IfName,
+				Address:   local,
+				Family:    "IPv4",
+				PrefixLen: int(prefixLen),
+				Scope:     scope,
+				Broadcast: broadcast,
+				Label:     label,
+			}
+			if family, _ := info["family"].(string); family == "inet6" {
+				detail.Family = "IPv6"
+			}
+			// The label of a primary IPv4 address is just the interface name
+			if detail.Label == link.IfName {
+				detail.Label = ""
+			}
+			for _, flag := range addrFlagNames {
+				if set, _ := info[flag].(bool); set {
+					detail.Flags = append(detail.Flags, flag)
+				}
+			}
+			if valid, ok := info["valid_life_time"].(float64); ok {
+				detail.ValidLifetime = lifetime(valid)
+			}
+			if preferred, ok := info["preferred_life_time"].(float64); ok {
+				detail.PreferredLifetime = lifetime(preferred)
+			}
+
+			details[link.IfName] = append(details[link.IfName], detail)
+		}
+	}
+	return details, nil
+}
+
+// lifetime converts a kernel lifetime to seconds or LifetimeForever
+func lifetime(seconds float64) *int64 {
+	value := int64(seconds)
+	if seconds >= lifetimeInfinity {
+		value = LifetimeForever
+	}
+	return &value
+}
+
+// readNetAddrDetails builds the details from net.Interfaces; scope comes from the
+// address class and, on Linux, IPv6 flags from /proc/net/if_inet6
+func readNetAddrDetails() (map[string][]AddressDetail, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, utils.WrapError(err, utils.ErrNetworkInterfaces, utils.ErrorTypeNetwork)
+	}
+	v6Flags := ipv6AddressFlags()
+
+	details := make(map[string][]AddressDetail)
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			prefixLen, _ := ipNet.Mask.Size()
+			detail := AddressDetail{
+				Interface: iface.Name,
+				Address:   ipNet.IP.String(),
+				Family:    ipFamily(ipNet.IP.String()),
+				PrefixLen: prefixLen,
+				Scope:     "global",
+			}
+			switch ClassifyIP(detail.Address).Class {
+			case IPClassLoopback:
+				detail.Scope = "host"
+			case IPClassLinkLocal:
+				detail.Scope = "link"
+			}
+			if flags, ok := v6Flags[detail.Address]; ok {
+				for _, f := range []struct {
+					bit  int
+					name string
+				}{
+					{ifaFlagTemporary, AddrFlagTemporary},
+					{ifaFlagDeprecated, AddrFlagDeprecated},
+					{ifaFlagTentative, AddrFlagTentative},
+					{ifaFlagDADFailed, AddrFlagDADFailed},
+				} {
+					if flags&f.bit != 0 {
+						detail.Flags = append(detail.Flags, f.name)
+					}
+				}
+			}
+			details[iface.Name] = append(details[iface.Name], detail)
+		}
+	}
+	return details, nil
+}
+
+// attachAddressDetails fills in the address details of each interface
+func attachAddressDetails(interfaces []InterfaceInfo) {
+	details, err := GetAddressDetails()
+	if err != nil {
+		return
+	}
+	for i := range interfaces {
+		interfaces[i].AddressDetails = details[interfaces[i].Name]
+	}
+}
+
+// formatLifetime formats a lifetime in seconds for tables
+func formatLifetime(seconds *int64) string {
+	switch {
+	case seconds == nil:
+		return "-"
+	case *seconds == LifetimeForever:
+		return "forever"
+	default:
+		return (time.Duration(*seconds) * time.Second).String()
+	}
+}
+
+// printAddressDetails draws the address details table and warns about DAD and SLAAC problems
+func printAddressDetails(details []AddressDetail) {
+	if len(details) == 0 {
+		return
+	}
+
+	var tableData [][]string
+	var problems []string
+	for _, detail := range details {
+		var flags []string
+		for _, flag := range detail.Flags {
+			switch flag {
+			case AddrFlagDADFailed:
+				flags = append(flags, display.Error(flag))
+			case AddrFlagTentative, AddrFlagDeprecated:
+				flags = append(flags, display.Warning(flag))
+			default:
+				flags = append(flags, flag)
+			}
+		}
+
+		preferred := formatLifetime(detail.PreferredLifetime)
+		if detail.PreferredLifetime != nil && *detail.PreferredLifetime == 0 {
+			preferred = display.Warning(preferred)
+		}
+
+		row := []string{
+			detail.Interface,
+			detail.CIDR(),
+			detail.Scope,
+			valueOrDash(detail.Broadcast),
+			valueOrDash(detail.Label),
+			valueOrDash(strings.Join(flags, ", ")),
+			formatLifetime(detail.ValidLifetime),
+			preferred,
+		}
+		tableData = append(tableData, row)
+
+		switch {
+		case detail.HasFlag(AddrFlagDADFailed):
+			problems = append(problems, display.Error(fmt.Sprintf("%s on %s: duplicate address detected, another host uses it", detail.Address, detail.Interface)))
+		case detail.HasFlag(AddrFlagTentative):
+			problems = append(problems, display.Warning(fmt.Sprintf("%s on %s: still running duplicate address detection, not usable yet", detail.Address, detail.Interface)))
+		case detail.HasFlag(AddrFlagDeprecated):
+			problems = append(problems, display.Warning(fmt.Sprintf("%s on %s: deprecated, kept only for existing connections", detail.Address, detail.Interface)))
+		}
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Address Details"
+	tableConfig.Headers = []string{"Interface", "Address", "Scope", "Broadcast", "Label", "Flags", "Valid", "Preferred"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 45
+
+	display.PrintTable(tableConfig)
+
+	for _, problem := range problems {
+		fmt.Println("  • " + problem)
+	}
+}
@@ -0,0 +1,227 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"netinfo/display"
+)
+
+// number of samples kept for the sparkline
+const sparklineWidth = 30
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// BandwidthSample holds the rates of one interface over one interval
+type BandwidthSample struct {
+	Time      time.Time `json:"time"`
+	Interface string    `json:"interface"`
+	Container string    `json:"container,omitempty"` // for veths leading into a container
+	RxBps     float64   `json:"rx_bps"`              // bits per second
+	TxBps     float64   `json:"tx_bps"`
+	RxPps     float64   `json:"rx_pps"` // packets per second
+	TxPps     float64   `json:"tx_pps"`
+	RxPeakBps float64   `json:"rx_peak_bps"` // since monitoring started
+	TxPeakBps float64   `json:"tx_peak_bps"`
+	RxAvgBps  float64   `json:"rx_avg_bps"`
+	TxAvgBps  float64   `json:"tx_avg_bps"`
+}
+
+// bandwidthTracker accumulates the peak, average and recent history of one interface
+type bandwidthTracker struct {
+	samples      int
+	rxSum, txSum float64
+	rxPeak       float64
+	txPeak       float64
+	history      []float64 // combined RX+TX bits per second, newest last
+}
+
+// add records one interval and fills in the running statistics of the sample
+func (t *bandwidthTracker) add(sample *BandwidthSample) {
+	t.samples++
+	t.rxSum += sample.RxBps
+	t.txSum += sample.TxBps
+	if sample.RxBps > t.rxPeak {
+		t.rxPeak = sample.RxBps
+	}
+	if sample.TxBps > t.txPeak {
+		t.txPeak = sample.TxBps
+	}
+	t.history = append(t.history, sample.RxBps+sample.TxBps)
+	if len(t.history) > sparklineWidth {
+		t.history = t.history[len(t.history)-sparklineWidth:]
+	}
+
+	sample.RxPeakBps = t.rxPeak
+	sample.TxPeakBps = t.txPeak
+	sample.RxAvgBps = t.rxSum / float64(t.samples)
+	sample.TxAvgBps = t.txSum / float64(t.samples)
+}
+
+// MonitorBandwidth samples the interface counters every interval until interrupted.
+// Only the named interfaces are shown when names is not empty. With stream set,
+// every sample is written to stdout as one JSON object per line instead of a table.
+func MonitorBandwidth(interval time.Duration, names []string, stream bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	previous, err := ReadInterfaceCounters()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if previous[name] == nil {
+			return fmt.Errorf("interface %s not found", name)
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	labels := vethLabels()
+	trackers := make(map[string]*bandwidthTracker)
+	encoder := json.NewEncoder(os.Stdout)
+	last := time.Now()
+
+	if !stream {
+		display.PrintInfo(fmt.Sprintf("Sampling every %s (Ctrl-C to stop)...", interval))
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := ReadInterfaceCounters()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		seconds := now.Sub(last).Seconds()
+		last = now
+
+		var samples []BandwidthSample
+		for name, after := range current {
+			before := previous[name]
+			if before == nil || (len(wanted) > 0 && !wanted[name]) {
+				continue
+			}
+			sample := BandwidthSample{
+				Time:      now,
+				Interface: name,
+				Container: labels[name],
+				RxBps:     float64(counterDelta(before.BytesRecv, after.BytesRecv)) * 8 / seconds,
+				TxBps:     float64(counterDelta(before.BytesSent, after.BytesSent)) * 8 / seconds,
+				RxPps:     float64(counterDelta(before.PacketsRecv, after.PacketsRecv)) / seconds,
+				TxPps:     float64(counterDelta(before.PacketsSent, after.PacketsSent)) / seconds,
+			}
+			if trackers[name] == nil {
+				trackers[name] = &bandwidthTracker{}
+			}
+			trackers[name].add(&sample)
+			samples = append(samples, sample)
+		}
+		previous = current
+
+		sort.Slice(samples, func(i, j int) bool {
+			return samples[i].Interface < samples[j].Interface
+		})
+
+		if stream {
+			for _, sample := range samples {
+				if err := encoder.Encode(sample); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		printBandwidth(samples, trackers, interval)
+	}
+}
+
+// counterDelta returns how far a counter moved, treating a reset or wrap as no traffic
+func counterDelta(before, after uint64) uint64 {
+	if after < before {
+		return 0
+	}
+	return after - before
+}
+
+// printBandwidth redraws the monitor table in place
+func printBandwidth(samples []BandwidthSample, trackers map[string]*bandwidthTracker, interval time.Duration) {
+	var tableData [][]string
+	for _, sample := range samples {
+		name := sample.Interface
+		if sample.Container != "" {
+			name = fmt.Sprintf("%s → %s", name, sample.Container)
+		}
+		row := []string{
+			name,
+			display.Success(formatBitRate(sample.RxBps)),
+			display.Info(formatBitRate(sample.TxBps)),
+			fmt.Sprintf("%.0f/s", sample.RxPps),
+			fmt.Sprintf("%.0f/s", sample.TxPps),
+			fmt.Sprintf("%s / %s", formatBitRate(sample.RxPeakBps), formatBitRate(sample.TxPeakBps)),
+			fmt.Sprintf("%s / %s", formatBitRate(sample.RxAvgBps), formatBitRate(sample.TxAvgBps)),
+			sparkline(trackers[sample.Interface].history),
+		}
+		tableData = append(tableData, row)
+	}
+
+	display.ClearScreen()
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = fmt.Sprintf("Interface Bandwidth - every %s, %s (Ctrl-C to stop)", interval, time.Now().Format("15:04:05"))
+	tableConfig.Headers = []string{"Interface", "RX", "TX", "RX Packets", "TX Packets", "Peak RX / TX", "Average RX / TX", "History"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 40
+
+	display.PrintTable(tableConfig)
+}
+
+// formatBitRate formats bits per second with decimal units
+func formatBitRate(bps float64) string {
+	units := []string{"bit/s", "kbit/s", "Mbit/s", "Gbit/s", "Tbit/s"}
+	unit := 0
+	for bps >= 1000 && unit < len(units)-1 {
+		bps /= 1000
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%.0f %s", bps, units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", bps, units[unit])
+}
+
+// sparkline draws values as block characters scaled to their maximum
+func sparkline(values []float64) string {
+	peak := 0.0
+	for _, value := range values {
+		if value > peak {
+			peak = value
+		}
+	}
+
+	var b strings.Builder
+	for _, value := range values {
+		level := 0
+		if peak > 0 {
+			level = int(value / peak * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
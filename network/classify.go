@@ -0,0 +1,348 @@
+package network
+
+import (
+	"bufio"
+	"encoding/hex"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+// Address classes
+const (
+	IPClassUnspecified   = "unspecified"
+	IPClassLoopback      = "loopback"
+	IPClassLinkLocal     = "link-local"
+	IPClassPrivate       = "private" // RFC 1918
+	IPClassCGNAT         = "cgnat"   // RFC 6598 shared address space
+	IPClassULA           = "ula"     // RFC 4193 unique local
+	IPClassGlobal        = "global"
+	IPClassMulticast     = "multicast"
+	IPClassBroadcast     = "broadcast"
+	IPClassDocumentation = "documentation"
+	IPClassBenchmarking  = "benchmarking"
+	IPClass6to4          = "6to4"
+	IPClassTeredo        = "teredo"
+	IPClassNAT64         = "nat64"
+	IPClassReserved      = "reserved"
+)
+
+// Extra labels attached to an address besides its class
+const (
+	IPLabelBogon      = "bogon"     // must never appear as a source on the public internet
+	IPLabelEUI64      = "eui-64"    // interface ID derived from a MAC address
+	IPLabelTemporary  = "temporary" // RFC 8981 privacy address
+	IPLabelDeprecated = "deprecated"
+	IPLabelRandomIID  = "random-iid" // stable-privacy or temporary interface ID
+)
+
+// Linux IFA_F_* address flags as found in /proc/net/if_inet6
+const (
+	ifaFlagTemporary   = 0x01
+	ifaFlagDADFailed   = 0x08
+	ifaFlagHomeAddress = 0x10 // Mobile IPv6 home address
+	ifaFlagDeprecated  = 0x20
+	ifaFlagTentative   = 0x40
+)
+
+// ipRange is one entry of the classification table
+type ipRange struct {
+	network *net.IPNet
+	class   string
+	bogon   bool
+}
+
+// Classification tables, most specific prefixes first
+var (
+	ipv4Ranges = mustRanges([]rangeSpec{
+		{"0.0.0.0/32", IPClassUnspecified, true},
+		{"0.0.0.0/8", IPClassReserved, true},
+		{"127.0.0.0/8", IPClassLoopback, true},
+		{"169.254.0.0/16", IPClassLinkLocal, true},
+		{"10.0.0.0/8", IPClassPrivate, true},
+		{"172.16.0.0/12", IPClassPrivate, true},
+		{"192.168.0.0/16", IPClassPrivate, true},
+		{"100.64.0.0/10", IPClassCGNAT, true},
+		{"192.0.2.0/24", IPClassDocumentation, true},
+		{"198.51.100.0/24", IPClassDocumentation, true},
+		{"203.0.113.0/24", IPClassDocumentation, true},
+		{"198.18.0.0/15", IPClassBenchmarking, true},
+		{"192.88.99.0/24", IPClass6to4, false}, // deprecated 6to4 relay anycast
+		{"192.0.0.0/24", IPClassReserved, true},
+		{"224.0.0.0/4", IPClassMulticast, false},
+		{"255.255.255.255/32", IPClassBroadcast, true},
+		{"240.0.0.0/4", IPClassReserved, true},
+	})
+	ipv6Ranges = mustRanges([]rangeSpec{
+		{"::/128", IPClassUnspecified, true},
+		{"::1/128", IPClassLoopback, true},
+		{"fe80::/10", IPClassLinkLocal, true},
+		{"fc00::/7", IPClassULA, true},
+		{"ff00::/8", IPClassMulticast, false},
+		{"2001:db8::/32", IPClassDocumentation, true},
+		{"3fff::/20", IPClassDocumentation, true},
+		{"2001:2::/48", IPClassBenchmarking, true},
+		{"2001::/32", IPClassTeredo, false},
+		{"2002::/16", IPClass6to4, false},
+		{"64:ff9b::/96", IPClassNAT64, false},
+		{"64:ff9b:1::/48", IPClassNAT64, true}, // local-use translation prefix
+		{"fec0::/10", IPClassReserved, true},   // deprecated site-local
+		{"2000::/3", IPClassGlobal, false},
+		{"::/0", IPClassReserved, true},
+	})
+)
+
+// rangeSpec is the textual form of an ipRange
+type rangeSpec struct {
+	cidr  string
+	class string
+	bogon bool
+}
+
+// mustRanges parses a classification table
+func mustRanges(specs []rangeSpec) []ipRange {
+	ranges := make([]ipRange, 0, len(specs))
+	for _, spec := range specs {
+		_, network, err := net.ParseCIDR(spec.cidr)
+		if err != nil {
+			panic(err)
+		}
+		ranges = append(ranges, ipRange{network: network, class: spec.class, bogon: spec.bogon})
+	}
+	return ranges
+}
+
+// IPClassification describes what kind of address an IP is
+type IPClassification struct {
+	Address      string   `json:"address"`
+	Family       string   `json:"family"`
+	Class        string   `json:"class"`
+	Labels       []string `json:"labels,omitempty"`
+	Global       bool     `json:"global"`                  // routable on the public internet
+	EmbeddedIPv4 string   `json:"embedded_ipv4,omitempty"` // 6to4, Teredo client or NAT64 address
+	EmbeddedMAC  string   `json:"embedded_mac,omitempty"`  // from an EUI-64 interface ID
+}
+
+// ClassifyIP classifies an address; a "/prefix" suffix is ignored
+func ClassifyIP(addr string) IPClassification {
+	return classifyIP(addr, nil)
+}
+
+// classifyIP is ClassifyIP with the local IPv6 address flags already read;
+// nil reads them only if the address needs them
+func classifyIP(addr string, v6Flags map[string]int) IPClassification {
+	addr = strings.SplitN(addr, "/", 2)[0]
+	result := IPClassification{Address: addr, Class: IPClassReserved}
+
+	ip := net.ParseIP(strings.SplitN(addr, "%", 2)[0])
+	if ip == nil {
+		result.Class = "invalid"
+		return result
+	}
+
+	ranges := ipv6Ranges
+	result.Family = "IPv6"
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		ranges = ipv4Ranges
+		result.Family = "IPv4"
+		result.Class = IPClassGlobal
+	}
+
+	bogon := false
+	for _, r := range ranges {
+		if r.network.Contains(ip) {
+			result.Class = r.class
+			bogon = r.bogon
+			break
+		}
+	}
+	result.Global = !bogon && result.Class != IPClassMulticast
+	if bogon {
+		result.Labels = append(result.Labels, IPLabelBogon)
+	}
+
+	if result.Family == "IPv6" {
+		classifyIPv6(ip, v6Flags, &result)
+	}
+
+	return result
+}
+
+// classifyIPv6 adds embedded addresses and interface ID labels
+func classifyIPv6(ip net.IP, v6Flags map[string]int, result *IPClassification) {
+	switch result.Class {
+	case IPClass6to4:
+		result.EmbeddedIPv4 = net.IP(ip[2:6]).String()
+	case IPClassTeredo:
+		client := make(net.IP, 4)
+		for i := range client {
+			client[i] = ip[12+i] ^ 0xff
+		}
+		result.EmbeddedIPv4 = client.String()
+	case IPClassNAT64:
+		result.EmbeddedIPv4 = net.IP(ip[12:16]).String()
+	}
+
+	// Interface ID properties only make sense for unicast addresses with a 64-bit IID
+	if result.Class != IPClassGlobal && result.Class != IPClassLinkLocal && result.Class != IPClassULA {
+		return
+	}
+	iid := ip[8:16]
+	if iid[3] == 0xff && iid[4] == 0xfe {
+		mac := net.HardwareAddr{iid[0] ^ 0x02, iid[1], iid[2], iid[5], iid[6], iid[7]}
+		result.Labels = append(result.Labels, IPLabelEUI64)
+		result.EmbeddedMAC = mac.String()
+		return
+	}
+
+	if v6Flags == nil {
+		v6Flags = ipv6AddressFlags()
+	}
+	if flags, ok := v6Flags[ip.String()]; ok {
+		if flags&ifaFlagTemporary != 0 {
+			result.Labels = append(result.Labels, IPLabelTemporary)
+		}
+		if flags&ifaFlagDeprecated != 0 {
+			result.Labels = append(result.Labels, IPLabelDeprecated)
+		}
+	}
+
+	// Manually assigned IDs tend to be small (::1, ::53); anything else looks random
+	if result.Class == IPClassGlobal && !hasLabel(result.Labels, IPLabelTemporary) && isRandomIID(iid) {
+		result.Labels = append(result.Labels, IPLabelRandomIID)
+	}
+}
+
+// isRandomIID reports whether an interface ID uses bits in its upper half
+func isRandomIID(iid []byte) bool {
+	for _, b := range iid[:4] {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLabel reports whether labels contains label
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// ipv6AddressFlags reads the kernel address flags of the local IPv6 addresses (Linux only)
+func ipv6AddressFlags() map[string]int {
+	flags := make(map[string]int)
+	if !utils.IsLinux() {
+		return flags
+	}
+
+	file, err := os.Open("/proc/net/if_inet6")
+	if err != nil {
+		return flags
+	}
+	defer file.Close()
+
+	// Format: address ifindex prefixlen scope flags ifname
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		raw, err := hex.DecodeString(fields[0])
+		if err != nil || len(raw) != net.IPv6len {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[4], 16, 32)
+		if err != nil {
+			continue
+		}
+		flags[net.IP(raw).String()] = int(value)
+	}
+	return flags
+}
+
+// classifyAddresses classifies a list of addresses, reading the IPv6 address flags once
+func classifyAddresses(addrs []string) []IPClassification {
+	var classifications []IPClassification
+	var v6Flags map[string]int
+	for _, addr := range addrs {
+		if v6Flags == nil && strings.Contains(addr, ":") {
+			v6Flags = ipv6AddressFlags()
+		}
+		classifications = append(classifications, classifyIP(addr, v6Flags))
+	}
+	return classifications
+}
+
+// addressClassRow pairs a classification with the interface holding the address
+type addressClassRow struct {
+	Interface      string
+	Classification IPClassification
+}
+
+// printAddressClasses displays the class and labels of each address
+func printAddressClasses(rows []addressClassRow) {
+	if len(rows) == 0 {
+		return
+	}
+
+	var tableData [][]string
+	for _, row := range rows {
+		c := row.Classification
+
+		// Local-only ranges are normal; unexpected non-routable ones are flagged
+		class := c.Class
+		switch c.Class {
+		case IPClassPrivate, IPClassLinkLocal, IPClassULA, IPClassLoopback, IPClassMulticast:
+		case IPClassCGNAT, IPClassDocumentation, IPClassBenchmarking, IPClassReserved, IPClassUnspecified, IPClassBroadcast:
+			class = display.Warning(class)
+		default:
+			if c.Global {
+				class = display.Success(class)
+			}
+		}
+
+		var details []string
+		for _, label := range c.Labels {
+			if label != IPLabelBogon {
+				details = append(details, label)
+			}
+		}
+		if c.EmbeddedIPv4 != "" {
+			details = append(details, "IPv4 "+c.EmbeddedIPv4)
+		}
+		if c.EmbeddedMAC != "" {
+			details = append(details, "MAC "+c.EmbeddedMAC)
+		}
+		detail := strings.Join(details, ", ")
+		if detail == "" {
+			detail = "-"
+		}
+
+		tableData = append(tableData, []string{
+			row.Interface,
+			c.Address,
+			class,
+			yesNo(c.Global),
+			detail,
+		})
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Address Classification"
+	tableConfig.Headers = []string{"Interface", "Address", "Class", "Global", "Details"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+
+	display.PrintTable(tableConfig)
+}
@@ -22,6 +22,8 @@ type ConnectionInfo struct {
 	Type       string `json:"type"`       // tcp, udp
 	LocalAddr  string `json:"local_addr"` // local IP:port
 	RemoteAddr string `json:"remote_addr"` // remote IP:port
+	RemoteName string `json:"remote_name,omitempty"` // PTR name of the remote IP
+	Geo        *GeoIPInfo `json:"geo,omitempty"`      // offline GeoIP/ASN of the remote IP
 	Status     string `json:"status"`     // ESTABLISHED, LISTEN, etc.
 	PID        int32  `json:"pid"`        // process ID
 	Process    string `json:"process"`    // process name
@@ -56,6 +58,18 @@ func ShowActiveConnections() error {
 	
 	display.PrintSuccess(fmt.Sprintf("Found %d active connections", connectionConfig.TotalCount))
 	
+	// Add PTR names for remote addresses
+	if ResolveNamesEnabled() {
+		display.PrintInfo("Resolving remote addresses...")
+		enrichConnectionNames(connectionConfig.Connections)
+	}
+	
+	// Add offline location and ASN for public remote addresses
+	showGeo := GeoIPConnectionsEnabled()
+	if showGeo {
+		enrichConnectionGeoIP(connectionConfig.Connections)
+	}
+	
 	// Sort connections by status, then by local address
 	sort.Slice(connectionConfig.Connections, func(i, j int) bool {
 		if connectionConfig.Connections[i].Status != connectionConfig.Connections[j].Status {
@@ -74,11 +88,11 @@ func ShowActiveConnections() error {
 		}
 		
 		// Format remote address
-		remoteAddr := conn.RemoteAddr
+		remoteAddr := withName(conn.RemoteAddr, conn.RemoteName)
 		if remoteAddr == "" {
 			remoteAddr = "-"
-		} else if len(remoteAddr) > 25 {
-			remoteAddr = utils.TruncateString(remoteAddr, 25)
+		} else if len(remoteAddr) > 45 {
+			remoteAddr = utils.TruncateString(remoteAddr, 45)
 		}
 		
 		// Format process name
@@ -108,6 +122,13 @@ func ShowActiveConnections() error {
 			fmt.Sprintf("%d", conn.PID),
 			process,
 		}
+		if showGeo {
+			location := conn.Geo.Summary()
+			if location == "" {
+				location = "-"
+			}
+			row = append(row, utils.TruncateString(location, 35))
+		}
 		
 		tableData = append(tableData, row)
 	}
@@ -115,6 +136,9 @@ func ShowActiveConnections() error {
 	tableConfig := display.NewTableConfig()
 	tableConfig.Title = "Active Network Connections"
 	tableConfig.Headers = []string{"Type", "Family", "Local Address", "Remote Address", "Status", "PID", "Process"}
+	if showGeo {
+		tableConfig.Headers = append(tableConfig.Headers, "Location")
+	}
 	tableConfig.Data = tableData
 	tableConfig.MaxWidth = 100
 	
@@ -218,6 +242,23 @@ func getActiveConnections() (*ConnectionConfig, error) {
 	return connectionConfig, nil
 }
 
+// enrichConnectionNames fills RemoteName from reverse DNS within the lookup deadline
+func enrichConnectionNames(connections []ConnectionInfo) {
+	var addrs []string
+	for _, conn := range connections {
+		if conn.RemoteAddr != "" {
+			addrs = append(addrs, hostFromAddr(conn.RemoteAddr))
+		}
+	}
+	
+	names := LookupPTRs(addrs, utils.PTRLookupDeadline)
+	for i := range connections {
+		if connections[i].RemoteAddr != "" {
+			connections[i].RemoteName = names[hostFromAddr(connections[i].RemoteAddr)]
+		}
+	}
+}
+
 // getConnectionType maps gopsutil connection type to string
 func getConnectionType(connType uint32) string {
 	switch connType {
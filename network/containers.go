@@ -0,0 +1,289 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"netinfo/utils"
+)
+
+var (
+	containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+	podUIDPattern      = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+)
+
+// where Docker keeps each container's config, holding its name
+const dockerContainersDir = "/var/lib/docker/containers"
+
+// maximum number of namespaces whose links are listed at once
+const namespaceWorkers = 8
+
+// VethPeer tells where the other end of a host-side veth lives
+type VethPeer struct {
+	PeerIndex   int    `json:"peer_index"`
+	PeerName    string `json:"peer_name,omitempty"` // name inside the namespace, usually eth0
+	Namespace   string `json:"namespace,omitempty"` // name from /var/run/netns or net:[inode]
+	PID         int    `json:"pid,omitempty"`
+	Process     string `json:"process,omitempty"`
+	Cgroup      string `json:"cgroup,omitempty"`
+	Runtime     string `json:"runtime,omitempty"` // docker, containerd, cri-o, podman
+	ContainerID string `json:"container_id,omitempty"`
+	PodUID      string `json:"pod_uid,omitempty"`
+	Name        string `json:"name,omitempty"` // container name, or the hostname inside
+}
+
+// Label returns a short description of the peer for tables
+func (v *VethPeer) Label() string {
+	switch {
+	case v.PodUID != "" && v.Name != "":
+		return "pod " + v.Name
+	case v.PodUID != "":
+		return "pod " + v.PodUID[:8]
+	case v.Runtime != "" && v.Name != "":
+		return v.Runtime + ":" + v.Name
+	case v.ContainerID != "" && v.Runtime != "":
+		return v.Runtime + ":" + v.ContainerID[:12]
+	case v.ContainerID != "":
+		return "container " + v.ContainerID[:12]
+	case v.Namespace != "" && !strings.HasPrefix(v.Namespace, "net:"):
+		return "netns " + v.Namespace
+	case v.PID != 0:
+		return fmt.Sprintf("pid %d (%s)", v.PID, v.Process)
+	}
+	return ""
+}
+
+// GetVethPeers finds, for every host-side veth whose peer is in another namespace,
+// that namespace, a process in it and the container the process belongs to
+func GetVethPeers() (map[string]*VethPeer, error) {
+	return findVethPeers(nil)
+}
+
+// findVethPeers is GetVethPeers restricted to the host veths in only (all when nil).
+// Listing another namespace's links takes a process per namespace, so the lookups run
+// in parallel and stop as soon as every veth has been matched.
+func findVethPeers(only map[string]bool) (map[string]*VethPeer, error) {
+	peers := make(map[string]*VethPeer)
+	// Entering a namespace needs CAP_SYS_ADMIN; without it every lookup would fail
+	if !utils.IsLinux() || os.Geteuid() != 0 {
+		return peers, nil
+	}
+
+	// Host veths with their peer in another namespace, by the ifindex of both ends
+	type vethKey struct {
+		peerIndex int
+		hostIndex int
+	}
+	veths := make(map[vethKey]string)
+	for name, link := range readIPLinks() {
+		if link.LinkInfo.InfoKind != LinkKindVeth || link.LinkNetnsID == nil {
+			continue
+		}
+		if only != nil && !only[name] {
+			continue
+		}
+		veths[vethKey{peerIndex: link.LinkIndex, hostIndex: link.IfIndex}] = name
+	}
+	if len(veths) == 0 {
+		return peers, nil
+	}
+
+	namespaces, err := ListNamespaces()
+	if err != nil {
+		return peers, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, namespaceWorkers)
+	for _, ns := range namespaces {
+		if ns.Current {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(ns Namespace) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			for _, link := range namespaceLinks(ctx, ns) {
+				name, ok := veths[vethKey{peerIndex: link.IfIndex, hostIndex: link.LinkIndex}]
+				if !ok {
+					continue
+				}
+				peer := &VethPeer{
+					PeerIndex: link.IfIndex,
+					PeerName:  link.IfName,
+					Namespace: ns.Name,
+					PID:       ns.PID,
+					Process:   ns.Process,
+				}
+				if peer.Namespace == "" {
+					peer.Namespace = ns.ID
+				}
+				if ns.PID != 0 {
+					identifyContainer(peer)
+				}
+
+				mu.Lock()
+				peers[name] = peer
+				if len(peers) == len(veths) {
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(ns)
+	}
+	wg.Wait()
+	return peers, nil
+}
+
+// namespaceLinks lists the links of another namespace
+func namespaceLinks(ctx context.Context, ns Namespace) []ipLink {
+	var output []byte
+	var err error
+	if ns.Name != "" {
+		output, err = utils.CommandWithTimeout(ctx, 5*time.Second, "ip", "-n", ns.Name, "-json", "link", "show")
+	} else {
+		output, err = utils.CommandWithTimeout(ctx, 5*time.Second, "nsenter", fmt.Sprintf("--net=/proc/%d/ns/net", ns.PID), "--", "ip", "-json", "link", "show")
+	}
+	if err != nil {
+		return nil
+	}
+
+	var links []ipLink
+	json.Unmarshal(output, &links)
+	return links
+}
+
+// identifyContainer fills in the cgroup, runtime, container ID and name of the peer's process
+func identifyContainer(peer *VethPeer) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", peer.PID))
+	if err != nil {
+		return
+	}
+
+	// Take the cgroup that names a container, preferring the v2 line ("0::/...")
+	named := false
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		path := parts[2]
+		container := containerIDPattern.MatchString(path) || podUIDPattern.MatchString(path)
+		switch {
+		case container && (parts[0] == "0" || !named):
+			peer.Cgroup, named = path, true
+		case !named && (peer.Cgroup == "" || parts[0] == "0"):
+			peer.Cgroup = path
+		}
+	}
+
+	peer.ContainerID = containerIDPattern.FindString(peer.Cgroup)
+	if match := podUIDPattern.FindStringSubmatch(peer.Cgroup); match != nil {
+		peer.PodUID = strings.ReplaceAll(match[1], "_", "-")
+	}
+	switch {
+	case strings.Contains(peer.Cgroup, "docker"):
+		peer.Runtime = "docker"
+	case strings.Contains(peer.Cgroup, "crio"):
+		peer.Runtime = "cri-o"
+	case strings.Contains(peer.Cgroup, "libpod"):
+		peer.Runtime = "podman"
+	case strings.Contains(peer.Cgroup, "containerd") || peer.PodUID != "":
+		peer.Runtime = "containerd"
+	}
+
+	if peer.Runtime == "docker" && peer.ContainerID != "" {
+		peer.Name = dockerContainerName(peer.ContainerID)
+	}
+	// Pods and most containers set their hostname to the pod or container name
+	if peer.Name == "" && (peer.ContainerID != "" || peer.PodUID != "") {
+		peer.Name = processEnv(peer.PID, "HOSTNAME")
+	}
+}
+
+// dockerContainerName reads the name of a container from Docker's state directory
+func dockerContainerName(id string) string {
+	data, err := os.ReadFile(filepath.Join(dockerContainersDir, id, "config.v2.json"))
+	if err != nil {
+		return ""
+	}
+	var config struct {
+		Name string `json:"Name"`
+	}
+	json.Unmarshal(data, &config)
+	return strings.TrimPrefix(config.Name, "/")
+}
+
+// processEnv returns one environment variable of a process
+func processEnv(pid int, key string) string {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "environ"))
+	if err != nil {
+		return ""
+	}
+	for _, entry := range strings.Split(string(data), "\x00") {
+		if value, ok := strings.CutPrefix(entry, key+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// AttachVethPeers sets the container of each host-side veth in interfaces.
+// It starts processes in other namespaces, so only views that show the result call it.
+func AttachVethPeers(interfaces []InterfaceInfo) {
+	veths := make(map[string]bool)
+	for _, iface := range interfaces {
+		if iface.Link != nil && iface.Link.Kind == LinkKindVeth {
+			veths[iface.Name] = true
+		}
+	}
+	if len(veths) == 0 {
+		return
+	}
+
+	peers, _ := findVethPeers(veths)
+	for i := range interfaces {
+		interfaces[i].Container = peers[interfaces[i].Name]
+	}
+}
+
+// vethLabels returns the container label of every host-side veth that has one
+func vethLabels() map[string]string {
+	labels := make(map[string]string)
+	peers, _ := GetVethPeers()
+	for name, peer := range peers {
+		if label := peer.Label(); label != "" {
+			labels[name] = label
+		}
+	}
+	return labels
+}
+
+// interfaceLabel returns the interface name with the container behind it, if any
+func interfaceLabel(iface InterfaceInfo) string {
+	if iface.Container != nil {
+		if label := iface.Container.Label(); label != "" {
+			return fmt.Sprintf("%s → %s", iface.Name, label)
+		}
+	}
+	return iface.Name
+}
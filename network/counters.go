@@ -0,0 +1,239 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+const sysClassNet = "/sys/class/net"
+
+// InterfaceCounters holds the traffic and error counters of one interface
+type InterfaceCounters struct {
+	BytesRecv   uint64 `json:"bytes_recv"`
+	BytesSent   uint64 `json:"bytes_sent"`
+	PacketsRecv uint64 `json:"packets_recv"`
+	PacketsSent uint64 `json:"packets_sent"`
+	ErrorsIn    uint64 `json:"errors_in"`
+	ErrorsOut   uint64 `json:"errors_out"`
+	DropsIn     uint64 `json:"drops_in"`
+	DropsOut    uint64 `json:"drops_out"`
+	FIFOIn      uint64 `json:"fifo_in"`
+	FIFOOut     uint64 `json:"fifo_out"`
+	Collisions  uint64 `json:"collisions"`
+	Multicast   uint64 `json:"multicast"` // received multicast packets
+	Source      string `json:"source"`    // sysfs or gopsutil
+
+	// Error and drop counters that increased between two samples
+	Growing []string `json:"growing,omitempty"`
+}
+
+// ReadInterfaceCounters returns the counters of every interface by name.
+// On Linux they come from /sys/class/net/*/statistics, elsewhere (or if sysfs is missing) from gopsutil.
+func ReadInterfaceCounters() (map[string]*InterfaceCounters, error) {
+	if utils.IsLinux() {
+		if counters, err := readSysfsCounters(); err == nil && len(counters) > 0 {
+			return counters, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.NetworkTimeout)
+	defer cancel()
+
+	// gopsutil reads /proc/net/dev on Linux; it has no collisions or multicast columns
+	stats, err := psnet.IOCountersWithContext(ctx, true)
+	if err != nil {
+		return nil, utils.WrapError(err, "Failed to read interface counters", utils.ErrorTypeNetwork)
+	}
+
+	counters := make(map[string]*InterfaceCounters)
+	for _, stat := range stats {
+		counters[stat.Name] = &InterfaceCounters{
+			BytesRecv:   stat.BytesRecv,
+			BytesSent:   stat.BytesSent,
+			PacketsRecv: stat.PacketsRecv,
+			PacketsSent: stat.PacketsSent,
+			ErrorsIn:    stat.Errin,
+			ErrorsOut:   stat.Errout,
+			DropsIn:     stat.Dropin,
+			DropsOut:    stat.Dropout,
+			FIFOIn:      stat.Fifoin,
+			FIFOOut:     stat.Fifoout,
+			Source:      "gopsutil",
+		}
+	}
+	return counters, nil
+}
+
+// readSysfsCounters reads /sys/class/net/<name>/statistics for every interface
+func readSysfsCounters() (map[string]*InterfaceCounters, error) {
+	entries, err := os.ReadDir(sysClassNet)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make(map[string]*InterfaceCounters)
+	for _, entry := range entries {
+		dir := filepath.Join(sysClassNet, entry.Name(), "statistics")
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+
+		read := func(name string) uint64 {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return 0
+			}
+			value, _ := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+			return value
+		}
+
+		counters[entry.Name()] = &InterfaceCounters{
+			BytesRecv:   read("rx_bytes"),
+			BytesSent:   read("tx_bytes"),
+			PacketsRecv: read("rx_packets"),
+			PacketsSent: read("tx_packets"),
+			ErrorsIn:    read("rx_errors"),
+			ErrorsOut:   read("tx_errors"),
+			DropsIn:     read("rx_dropped"),
+			DropsOut:    read("tx_dropped"),
+			FIFOIn:      read("rx_fifo_errors"),
+			FIFOOut:     read("tx_fifo_errors"),
+			Collisions:  read("collisions"),
+			Multicast:   read("multicast"),
+			Source:      "sysfs",
+		}
+	}
+	return counters, nil
+}
+
+// attachCounters fills in the counters of each interface, leaving them nil when unavailable
+func attachCounters(interfaces []InterfaceInfo) {
+	counters, err := ReadInterfaceCounters()
+	if err != nil {
+		return
+	}
+	for i := range interfaces {
+		interfaces[i].Counters = counters[interfaces[i].Name]
+	}
+}
+
+// TrackCounterGrowth samples the counters again after interval and
+// records which error and drop counters went up in the meantime
+func TrackCounterGrowth(interfaces []InterfaceInfo, interval time.Duration) {
+	time.Sleep(interval)
+
+	later, err := ReadInterfaceCounters()
+	if err != nil {
+		return
+	}
+	for i := range interfaces {
+		before, after := interfaces[i].Counters, later[interfaces[i].Name]
+		if before == nil || after == nil {
+			continue
+		}
+		after.Growing = growingCounters(before, after)
+		interfaces[i].Counters = after
+	}
+}
+
+// growingCounters names the error and drop counters that increased from before to after
+func growingCounters(before, after *InterfaceCounters) []string {
+	checks := []struct {
+		name          string
+		before, after uint64
+	}{
+		{"errors_in", before.ErrorsIn, after.ErrorsIn},
+		{"errors_out", before.ErrorsOut, after.ErrorsOut},
+		{"drops_in", before.DropsIn, after.DropsIn},
+		{"drops_out", before.DropsOut, after.DropsOut},
+		{"fifo_in", before.FIFOIn, after.FIFOIn},
+		{"fifo_out", before.FIFOOut, after.FIFOOut},
+		{"collisions", before.Collisions, after.Collisions},
+	}
+
+	var growing []string
+	for _, check := range checks {
+		if check.after > check.before {
+			growing = append(growing, check.name)
+		}
+	}
+	return growing
+}
+
+// printInterfaceCounters draws the statistics table; errors and drops are
+// yellow when non-zero and red when they grew while sampling
+func printInterfaceCounters(interfaces []InterfaceInfo) {
+	var tableData [][]string
+	var troubled []string
+	for _, iface := range interfaces {
+		c := iface.Counters
+		if c == nil {
+			continue
+		}
+
+		growing := make(map[string]bool)
+		for _, name := range c.Growing {
+			growing[name] = true
+		}
+		pair := func(inName string, in uint64, outName string, out uint64) string {
+			text := fmt.Sprintf("%d / %d", in, out)
+			switch {
+			case growing[inName] || growing[outName]:
+				return display.Error(text + " ↑")
+			case in > 0 || out > 0:
+				return display.Warning(text)
+			default:
+				return display.Muted(text)
+			}
+		}
+
+		collisions := fmt.Sprintf("%d", c.Collisions)
+		if growing["collisions"] {
+			collisions = display.Error(collisions + " ↑")
+		} else if c.Collisions > 0 {
+			collisions = display.Warning(collisions)
+		}
+
+		row := []string{
+			interfaceLabel(iface),
+			fmt.Sprintf("%s / %s", utils.FormatBytes(c.BytesRecv), utils.FormatBytes(c.BytesSent)),
+			fmt.Sprintf("%d / %d", c.PacketsRecv, c.PacketsSent),
+			pair("errors_in", c.ErrorsIn, "errors_out", c.ErrorsOut),
+			pair("drops_in", c.DropsIn, "drops_out", c.DropsOut),
+			pair("fifo_in", c.FIFOIn, "fifo_out", c.FIFOOut),
+			collisions,
+			fmt.Sprintf("%d", c.Multicast),
+		}
+		tableData = append(tableData, row)
+
+		if len(c.Growing) > 0 {
+			troubled = append(troubled, fmt.Sprintf("%s (%s)", iface.Name, strings.Join(c.Growing, ", ")))
+		}
+	}
+
+	if len(tableData) == 0 {
+		return
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Interface Statistics (RX / TX)"
+	tableConfig.Headers = []string{"Interface", "Bytes", "Packets", "Errors", "Drops", "FIFO", "Collisions", "Multicast"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 30
+
+	display.PrintTable(tableConfig)
+
+	if len(troubled) > 0 {
+		display.PrintWarning(fmt.Sprintf("Errors or drops are increasing on: %s", strings.Join(troubled, "; ")))
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"netinfo/display"
 	"netinfo/utils"
+
+	"github.com/miekg/dns"
 )
 
 // DNSInfo holds DNS server information
@@ -25,6 +28,7 @@ type DNSInfo struct {
 type DNSConfig struct {
 	Servers    []DNSInfo `json:"servers"`
 	SearchList []string  `json:"search_list"`
+	NDots      int       `json:"ndots"`
 }
 
 // PowerShell DNS command for Windows
@@ -34,15 +38,7 @@ const windowsDNSCmd = `Get-DnsClientServerAddress -AddressFamily IPv4,IPv6 | Sel
 func ShowDNSInformation() error {
 	display.PrintInfo("Gathering DNS server information...")
 	
-	var dnsConfig *DNSConfig
-	var err error
-	
-	if utils.IsWindows() {
-		dnsConfig, err = getWindowsDNS()
-	} else {
-		dnsConfig, err = getLinuxDNS()
-	}
-	
+	dnsConfig, err := getDNSConfig()
 	if err != nil {
 		display.PrintError(fmt.Sprintf("Failed to get DNS information: %v", err))
 		return err
@@ -114,9 +110,77 @@ func ShowDNSInformation() error {
 	display.PrintInfo(fmt.Sprintf("  • Total IPv6 DNS servers: %d", totalIPv6))
 	display.PrintInfo(fmt.Sprintf("  • DNS search domains: %d", len(dnsConfig.SearchList)))
 	
+	// Check whether the configured resolvers validate DNSSEC
+	showResolverDNSSEC(dnsConfig)
+	
+	// Compare answers against the reference resolver to spot hijacking
+	showDNSIntegrity(dnsConfig)
+	
 	return nil
 }
 
+// getDNSConfig retrieves the DNS configuration for the current platform
+func getDNSConfig() (*DNSConfig, error) {
+	if utils.IsWindows() {
+		return getWindowsDNS()
+	}
+	return getLinuxDNS()
+}
+
+// uniqueDNSServers returns every configured DNS server once, in configuration order
+func uniqueDNSServers(dnsConfig *DNSConfig) []string {
+	var servers []string
+	seen := make(map[string]bool)
+	for _, dnsInfo := range dnsConfig.Servers {
+		for _, server := range dnsInfo.All {
+			if !seen[server] {
+				seen[server] = true
+				servers = append(servers, server)
+			}
+		}
+	}
+	return servers
+}
+
+// queryDNS sends a single query for name/qtype to server.
+// With dnssec set the query carries the DO bit so signatures are returned, and the CD bit
+// so the resolver hands back data even when its own validation fails.
+func queryDNS(server, name string, qtype uint16, dnssec bool) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	msg.RecursionDesired = true
+	msg.AuthenticatedData = true
+	msg.SetEdns0(4096, dnssec)
+	msg.CheckingDisabled = dnssec
+	
+	return exchangeDNS(server, msg)
+}
+
+// exchangeDNS sends msg to server over UDP, retrying over TCP when the answer is truncated
+func exchangeDNS(server string, msg *dns.Msg) (*dns.Msg, error) {
+	address := dnsServerAddress(server)
+	
+	client := &dns.Client{Timeout: utils.DNSQueryTimeout}
+	resp, _, err := client.Exchange(msg, address)
+	if err == nil && resp.Truncated {
+		client.Net = "tcp"
+		resp, _, err = client.Exchange(msg, address)
+	}
+	if err != nil {
+		return nil, utils.WrapError(err, fmt.Sprintf("DNS query to %s failed", server), utils.ErrorTypeNetwork)
+	}
+	
+	return resp, nil
+}
+
+// dnsServerAddress adds the default DNS port to a server address if it has none
+func dnsServerAddress(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(server, "53")
+}
+
 // getWindowsDNS retrieves DNS information on Windows using PowerShell
 func getWindowsDNS() (*DNSConfig, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -135,7 +199,7 @@ func getWindowsDNS() (*DNSConfig, error) {
 		return nil, fmt.Errorf("failed to parse PowerShell output: %v", err)
 	}
 	
-	var dnsConfig DNSConfig
+	dnsConfig := DNSConfig{NDots: 1}
 	
 	for _, entry := range dnsEntries {
 		interfaceName, ok := entry["InterfaceAlias"].(string)
@@ -181,6 +245,7 @@ func getLinuxDNS() (*DNSConfig, error) {
 	dnsConfig := &DNSConfig{
 		Servers:    []DNSInfo{},
 		SearchList: []string{},
+		NDots:      1,
 	}
 	
 	// Read /etc/resolv.conf
@@ -226,9 +291,16 @@ func getLinuxDNS() (*DNSConfig, error) {
 				}
 			}
 		case "search":
-			if len(fields) >= 2 {
-				for i := 1; i < len(fields); i++ {
-					dnsConfig.SearchList = append(dnsConfig.SearchList, fields[i])
+			// The last search or domain line wins
+			dnsConfig.SearchList = append([]string{}, fields[1:]...)
+		case "domain":
+			dnsConfig.SearchList = []string{fields[1]}
+		case "options":
+			for _, option := range fields[1:] {
+				if value, ok := strings.CutPrefix(option, "ndots:"); ok {
+					if n, err := strconv.Atoi(value); err == nil {
+						dnsConfig.NDots = min(n, 15)
+					}
 				}
 			}
 		}
@@ -292,11 +364,7 @@ func TestDNSResolution(hostname string) error {
 
 // GetDNSServersByInterface returns DNS servers for a specific interface
 func GetDNSServersByInterface(interfaceName string) (*DNSInfo, error) {
-	dnsConfig, err := getWindowsDNS()
-	if utils.IsLinux() {
-		dnsConfig, err = getLinuxDNS()
-	}
-	
+	dnsConfig, err := getDNSConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,297 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"netinfo/display"
+	"netinfo/utils"
+
+	"github.com/miekg/dns"
+)
+
+// DNS integrity verdicts
+const (
+	DNSVerdictClean        = "CLEAN"
+	DNSVerdictInconsistent = "INCONSISTENT"
+	DNSVerdictHijacked     = "HIJACKED"
+	DNSVerdictUnreachable  = "UNREACHABLE"
+)
+
+// number of random nonexistent names used to detect NXDOMAIN rewriting
+const nxdomainProbes = 2
+
+// DNSTypeAnswer holds a resolver's answer to one query type for a name
+type DNSTypeAnswer struct {
+	Rcode     string   `json:"rcode,omitempty"`
+	Addresses []string `json:"addresses"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// DNSAnswer holds a resolver's A and AAAA answers for one name
+type DNSAnswer struct {
+	Name      string        `json:"name"`
+	A         DNSTypeAnswer `json:"a"`
+	AAAA      DNSTypeAnswer `json:"aaaa"`
+	Addresses []string      `json:"addresses"`       // A and AAAA together
+	Error     string        `json:"error,omitempty"` // set only when both queries failed
+}
+
+// DNSIntegrityResult holds the integrity verdict of a single resolver
+type DNSIntegrityResult struct {
+	Server            string      `json:"server"`
+	Reference         bool        `json:"reference"`
+	Answers           []DNSAnswer `json:"answers"`
+	Mismatches        []string    `json:"mismatches"`         // names and types whose answers share nothing with the reference
+	PrivateAnswers    []string    `json:"private_answers"`    // public names answered with non-public addresses
+	NXDOMAINRewritten bool        `json:"nxdomain_rewritten"` // nonexistent names returned addresses
+	Verdict           string      `json:"verdict"`
+}
+
+// CheckDNSIntegrity queries the same names from every resolver and the reference resolver and compares them
+func CheckDNSIntegrity(servers []string) []DNSIntegrityResult {
+	cfg := utils.GetConfig()
+	names := cfg.DNSCheckNames
+
+	probes := make([]string, nxdomainProbes)
+	for i := range probes {
+		probes[i] = randomNXName()
+	}
+
+	// A configured server that is also the reference is queried once and not compared with itself
+	allServers := append([]string{}, servers...)
+	referenceIndex := -1
+	if cfg.ReferenceResolver != "" {
+		for i, server := range servers {
+			if server == cfg.ReferenceResolver {
+				referenceIndex = i
+			}
+		}
+		if referenceIndex < 0 {
+			allServers = append(allServers, cfg.ReferenceResolver)
+			referenceIndex = len(allServers) - 1
+		}
+	}
+
+	results := make([]DNSIntegrityResult, len(allServers))
+	var wg sync.WaitGroup
+	for i, server := range allServers {
+		wg.Add(1)
+		go func(i int, server string) {
+			defer wg.Done()
+			results[i] = queryIntegrity(server, names, probes)
+		}(i, server)
+	}
+	wg.Wait()
+
+	var reference *DNSIntegrityResult
+	if referenceIndex >= 0 {
+		reference = &results[referenceIndex]
+		reference.Reference = true
+	}
+
+	for i := range results {
+		result := &results[i]
+		if result.Verdict == DNSVerdictUnreachable {
+			continue
+		}
+
+		if reference != nil && !result.Reference && reference.Verdict != DNSVerdictUnreachable {
+			for j, answer := range result.Answers {
+				if !answersOverlap(answer.A, reference.Answers[j].A) {
+					result.Mismatches = append(result.Mismatches, answer.Name+" (A)")
+				}
+				if !answersOverlap(answer.AAAA, reference.Answers[j].AAAA) {
+					result.Mismatches = append(result.Mismatches, answer.Name+" (AAAA)")
+				}
+			}
+		}
+
+		switch {
+		case result.NXDOMAINRewritten || len(result.PrivateAnswers) > 0:
+			result.Verdict = DNSVerdictHijacked
+		case len(result.Mismatches) > 0:
+			result.Verdict = DNSVerdictInconsistent
+		default:
+			result.Verdict = DNSVerdictClean
+		}
+	}
+
+	return results
+}
+
+// queryIntegrity asks one resolver for every check name and NXDOMAIN probe
+func queryIntegrity(server string, names, probes []string) DNSIntegrityResult {
+	result := DNSIntegrityResult{Server: server}
+
+	// Unreachable only when every single query failed
+	queries, failures := 0, 0
+	countFailures := func(answer DNSAnswer) {
+		for _, typed := range []DNSTypeAnswer{answer.A, answer.AAAA} {
+			queries++
+			if typed.Error != "" {
+				failures++
+			}
+		}
+	}
+
+	for _, name := range names {
+		answer := lookupAddresses(server, name)
+		countFailures(answer)
+		for _, addr := range answer.Addresses {
+			if !isPublicAddress(addr) {
+				result.PrivateAnswers = append(result.PrivateAnswers, fmt.Sprintf("%s -> %s", name, addr))
+			}
+		}
+		result.Answers = append(result.Answers, answer)
+	}
+
+	for _, probe := range probes {
+		answer := lookupAddresses(server, probe)
+		countFailures(answer)
+		// Either type answering is enough, rewriters often only forge A records
+		if len(answer.Addresses) > 0 {
+			result.NXDOMAINRewritten = true
+		}
+	}
+
+	if failures == queries {
+		result.Verdict = DNSVerdictUnreachable
+	}
+
+	return result
+}
+
+// lookupAddresses returns the sorted A and AAAA records a resolver gives for name
+func lookupAddresses(server, name string) DNSAnswer {
+	answer := DNSAnswer{
+		Name: name,
+		A:    lookupType(server, name, dns.TypeA),
+		AAAA: lookupType(server, name, dns.TypeAAAA),
+	}
+
+	answer.Addresses = append(append([]string{}, answer.A.Addresses...), answer.AAAA.Addresses...)
+	sort.Strings(answer.Addresses)
+	if answer.A.Error != "" && answer.AAAA.Error != "" {
+		answer.Error = answer.A.Error
+	}
+	return answer
+}
+
+// lookupType returns the sorted addresses of one query type
+func lookupType(server, name string, qtype uint16) DNSTypeAnswer {
+	answer := DNSTypeAnswer{Addresses: []string{}}
+
+	resp, err := queryDNS(server, name, qtype, false)
+	if err != nil {
+		answer.Error = err.Error()
+		return answer
+	}
+	answer.Rcode = dns.RcodeToString[resp.Rcode]
+	for _, rr := range resp.Answer {
+		switch record := rr.(type) {
+		case *dns.A:
+			answer.Addresses = append(answer.Addresses, record.A.String())
+		case *dns.AAAA:
+			answer.Addresses = append(answer.Addresses, record.AAAA.String())
+		}
+	}
+
+	sort.Strings(answer.Addresses)
+	return answer
+}
+
+// answersOverlap reports whether two answers to the same query share an address.
+// Names served from CDNs legitimately vary, so only completely disjoint answers count.
+func answersOverlap(a, b DNSTypeAnswer) bool {
+	if a.Error != "" || b.Error != "" {
+		return true
+	}
+	if len(a.Addresses) == 0 && len(b.Addresses) == 0 {
+		return a.Rcode == b.Rcode
+	}
+	seen := make(map[string]bool)
+	for _, addr := range b.Addresses {
+		seen[addr] = true
+	}
+	for _, addr := range a.Addresses {
+		if seen[addr] {
+			return true
+		}
+	}
+	return false
+}
+
+// isPublicAddress reports whether addr may legitimately be the answer for a public name
+func isPublicAddress(addr string) bool {
+	classification := ClassifyIP(addr)
+	return classification.Global && classification.Class != IPClassDocumentation
+}
+
+// randomNXName returns a name that should not exist under a public TLD
+func randomNXName() string {
+	buf := make([]byte, 10)
+	rand.Read(buf)
+	return "netinfo-nx-" + hex.EncodeToString(buf) + ".com"
+}
+
+// showDNSIntegrity prints the integrity verdict table for the configured resolvers
+func showDNSIntegrity(dnsConfig *DNSConfig) {
+	servers := uniqueDNSServers(dnsConfig)
+	if len(servers) == 0 {
+		return
+	}
+
+	display.PrintInfo("Comparing resolver answers against the reference resolver...")
+	results := CheckDNSIntegrity(servers)
+
+	var tableData [][]string
+	for _, result := range results {
+		server := result.Server
+		if result.Reference {
+			server += " (reference)"
+		}
+
+		mismatches := "-"
+		if len(result.Mismatches) > 0 {
+			mismatches = strings.Join(result.Mismatches, ", ")
+		}
+		private := "-"
+		if len(result.PrivateAnswers) > 0 {
+			private = strings.Join(result.PrivateAnswers, ", ")
+		}
+
+		row := []string{
+			server,
+			mismatches,
+			yesNo(result.NXDOMAINRewritten),
+			private,
+			dnsVerdictColor(result.Verdict),
+		}
+		tableData = append(tableData, row)
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "DNS Integrity"
+	tableConfig.Headers = []string{"Resolver", "Differs From Reference", "NXDOMAIN Rewriting", "Private Answers", "Verdict"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 50
+
+	display.PrintTable(tableConfig)
+}
+
+// dnsVerdictColor colors a DNS integrity verdict for display
+func dnsVerdictColor(verdict string) string {
+	switch verdict {
+	case DNSVerdictClean:
+		return display.Success(verdict)
+	case DNSVerdictHijacked:
+		return display.Error(verdict)
+	default:
+		return display.Warning(verdict)
+	}
+}
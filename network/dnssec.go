@@ -0,0 +1,550 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+
+	"github.com/miekg/dns"
+)
+
+// DNSSEC probe names: a zone that is correctly signed and one whose signatures are deliberately broken
+const (
+	DNSSECSignedProbe = "ietf.org."
+	DNSSECBogusProbe  = "dnssec-failed.org."
+)
+
+// DNSSEC validation states
+const (
+	DNSSECSecure        = "SECURE"
+	DNSSECInsecure      = "INSECURE"
+	DNSSECBogus         = "BOGUS"
+	DNSSECIndeterminate = "INDETERMINATE"
+)
+
+// RootTrustAnchors are the DS records of the root zone KSKs (KSK-2017 and KSK-2024)
+var RootTrustAnchors = []*dns.DS{
+	{
+		Hdr:        dns.RR_Header{Name: ".", Rrtype: dns.TypeDS, Class: dns.ClassINET},
+		KeyTag:     20326,
+		Algorithm:  dns.RSASHA256,
+		DigestType: dns.SHA256,
+		Digest:     "E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+	},
+	{
+		Hdr:        dns.RR_Header{Name: ".", Rrtype: dns.TypeDS, Class: dns.ClassINET},
+		KeyTag:     38696,
+		Algorithm:  dns.RSASHA256,
+		DigestType: dns.SHA256,
+		Digest:     "683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16",
+	},
+}
+
+// ResolverDNSSECStatus holds the DNSSEC behaviour of a single resolver
+type ResolverDNSSECStatus struct {
+	Server       string `json:"server"`
+	ADBit        bool   `json:"ad_bit"`        // sets AD on a signed answer
+	RejectsBogus bool   `json:"rejects_bogus"` // returns SERVFAIL for a broken signature
+	Validating   bool   `json:"validating"`
+	Error        string `json:"error,omitempty"`
+}
+
+// DNSSECLink holds the validation result of one link in a chain of trust
+type DNSSECLink struct {
+	Zone   string `json:"zone"`
+	DS     string `json:"ds"`     // DS in the parent matches a DNSKEY
+	DNSKEY string `json:"dnskey"` // DNSKEY RRset is signed by a trusted key
+	RRSIG  string `json:"rrsig"`  // signature over the DS (or answer) RRset
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// DNSSECChain holds the full chain of trust for a name
+type DNSSECChain struct {
+	Name   string       `json:"name"`
+	Type   string       `json:"type"`
+	Server string       `json:"server"`
+	Links  []DNSSECLink `json:"links"`
+	Status string       `json:"status"`
+}
+
+// CheckResolverDNSSEC tests whether a resolver sets the AD bit and rejects bogus signatures
+func CheckResolverDNSSEC(server string) ResolverDNSSECStatus {
+	status := ResolverDNSSECStatus{Server: server}
+
+	resp, err := queryDNSValidated(server, DNSSECSignedProbe, dns.TypeA)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.ADBit = resp.Rcode == dns.RcodeSuccess && resp.AuthenticatedData
+
+	resp, err = queryDNSValidated(server, DNSSECBogusProbe, dns.TypeA)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.RejectsBogus = resp.Rcode == dns.RcodeServerFailure
+
+	status.Validating = status.ADBit && status.RejectsBogus
+	return status
+}
+
+// queryDNSValidated asks the resolver to validate (DO set, CD clear)
+func queryDNSValidated(server, name string, qtype uint16) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	msg.RecursionDesired = true
+	msg.AuthenticatedData = true
+	msg.SetEdns0(4096, true)
+
+	return exchangeDNS(server, msg)
+}
+
+// ValidateDNSSECChain walks the chain of trust from the root to name and validates each link locally.
+// The resolver is only used to fetch records; all signatures are checked against RootTrustAnchors.
+func ValidateDNSSECChain(server, name string, qtype uint16) (*DNSSECChain, error) {
+	name = dns.CanonicalName(name)
+	chain := &DNSSECChain{
+		Name:   name,
+		Type:   dns.TypeToString[qtype],
+		Server: server,
+		Status: DNSSECSecure,
+	}
+
+	zones, err := findZoneCuts(server, name)
+	if err != nil {
+		return nil, err
+	}
+
+	trustedDS := RootTrustAnchors
+	var parentKeys []*dns.DNSKEY
+
+	for _, zone := range zones {
+		link := DNSSECLink{Zone: zone}
+
+		// DS set: built-in anchors for the root, otherwise fetched from the parent
+		if zone != "." {
+			dsSet, dsSigs, err := fetchRRset(server, zone, dns.TypeDS)
+			if err != nil {
+				return nil, err
+			}
+			if len(dsSet) == 0 {
+				// Without a validated denial the DS may simply have been stripped on the way
+				status, detail, err := verifyNoDS(server, zone, parentKeys)
+				if err != nil {
+					return nil, err
+				}
+				link.DS = "none"
+				link.DNSKEY = "-"
+				link.RRSIG = "-"
+				link.Status = status
+				link.Detail = detail
+				chain.Links = append(chain.Links, link)
+				chain.Status = status
+				return chain, nil
+			}
+			link.RRSIG, link.Detail = verifyRRset(dsSet, dsSigs, parentKeys)
+			trustedDS = nil
+			for _, rr := range dsSet {
+				if ds, ok := rr.(*dns.DS); ok {
+					trustedDS = append(trustedDS, ds)
+				}
+			}
+		} else {
+			link.RRSIG = "anchor"
+		}
+
+		keySet, keySigs, err := fetchRRset(server, zone, dns.TypeDNSKEY)
+		if err != nil {
+			return nil, err
+		}
+		keys := dnskeys(keySet)
+
+		// At least one DNSKEY must hash to a trusted DS
+		var entryKeys []*dns.DNSKEY
+		for _, key := range keys {
+			if matchesDS(key, trustedDS) {
+				entryKeys = append(entryKeys, key)
+			}
+		}
+		switch {
+		case len(keys) == 0:
+			link.DS = "no DNSKEY"
+		case len(entryKeys) == 0:
+			link.DS = "mismatch"
+		default:
+			link.DS = fmt.Sprintf("ok (tag %d)", entryKeys[0].KeyTag())
+		}
+
+		// The DNSKEY RRset must be self-signed by one of those entry keys
+		var keyDetail string
+		link.DNSKEY, keyDetail = verifyRRset(keySet, keySigs, entryKeys)
+		if link.Detail == "" {
+			link.Detail = keyDetail
+		}
+
+		link.Status = DNSSECSecure
+		if len(entryKeys) == 0 || link.DNSKEY != "valid" || (link.RRSIG != "valid" && link.RRSIG != "anchor") {
+			link.Status = DNSSECBogus
+			chain.Status = DNSSECBogus
+		}
+		chain.Links = append(chain.Links, link)
+
+		if chain.Status == DNSSECBogus {
+			return chain, nil
+		}
+		parentKeys = keys
+	}
+
+	// Finally the answer itself, signed by the closest zone
+	answer := DNSSECLink{Zone: fmt.Sprintf("%s %s", name, chain.Type), DS: "-", DNSKEY: "-"}
+	rrset, sigs, err := fetchRRset(server, name, qtype)
+	if err != nil {
+		return nil, err
+	}
+	if len(rrset) == 0 {
+		answer.RRSIG = "-"
+		answer.Status = DNSSECIndeterminate
+		answer.Detail = "no records of this type"
+		if chain.Status == DNSSECSecure {
+			chain.Status = DNSSECIndeterminate
+		}
+	} else {
+		answer.RRSIG, answer.Detail = verifyRRset(rrset, sigs, parentKeys)
+		answer.Status = DNSSECSecure
+		if answer.RRSIG != "valid" {
+			answer.Status = DNSSECBogus
+			chain.Status = DNSSECBogus
+		}
+	}
+	chain.Links = append(chain.Links, answer)
+
+	return chain, nil
+}
+
+// findZoneCuts returns the zone apexes from the root down to the zone containing name
+func findZoneCuts(server, name string) ([]string, error) {
+	zones := []string{"."}
+	labels := dns.SplitDomainName(name)
+	for i := len(labels) - 1; i >= 0; i-- {
+		candidate := dns.Fqdn(strings.Join(labels[i:], "."))
+		resp, err := queryDNS(server, candidate, dns.TypeSOA, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, rr := range resp.Answer {
+			if soa, ok := rr.(*dns.SOA); ok && dns.CanonicalName(soa.Hdr.Name) == candidate {
+				zones = append(zones, candidate)
+				break
+			}
+		}
+	}
+	return zones, nil
+}
+
+// fetchRRset returns the records of qtype owned by name together with their signatures
+func fetchRRset(server, name string, qtype uint16) ([]dns.RR, []*dns.RRSIG, error) {
+	resp, err := queryDNS(server, name, qtype, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rrset []dns.RR
+	var sigs []*dns.RRSIG
+	for _, rr := range resp.Answer {
+		if !strings.EqualFold(rr.Header().Name, name) {
+			continue
+		}
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			if sig.TypeCovered == qtype {
+				sigs = append(sigs, sig)
+			}
+		} else if rr.Header().Rrtype == qtype {
+			rrset = append(rrset, rr)
+		}
+	}
+	return rrset, sigs, nil
+}
+
+// verifyNoDS checks the signed NSEC or NSEC3 proof that the parent has no DS for zone.
+// The delegation is only DNSSECInsecure when that proof validates with the parent's keys;
+// a missing proof is DNSSECIndeterminate and a forged or incomplete one DNSSECBogus.
+func verifyNoDS(server, zone string, parentKeys []*dns.DNSKEY) (string, string, error) {
+	resp, err := queryDNS(server, zone, dns.TypeDS, true)
+	if err != nil {
+		return "", "", err
+	}
+
+	// NSEC/NSEC3 RRsets of the authority section, keyed by owner and type
+	type rrsetKey struct {
+		name   string
+		rrtype uint16
+	}
+	rrsets := make(map[rrsetKey][]dns.RR)
+	sigs := make(map[rrsetKey][]*dns.RRSIG)
+	for _, rr := range resp.Ns {
+		name := dns.CanonicalName(rr.Header().Name)
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			if sig.TypeCovered == dns.TypeNSEC || sig.TypeCovered == dns.TypeNSEC3 {
+				key := rrsetKey{name, sig.TypeCovered}
+				sigs[key] = append(sigs[key], sig)
+			}
+		} else if rrtype := rr.Header().Rrtype; rrtype == dns.TypeNSEC || rrtype == dns.TypeNSEC3 {
+			key := rrsetKey{name, rrtype}
+			rrsets[key] = append(rrsets[key], rr)
+		}
+	}
+	if len(rrsets) == 0 {
+		return DNSSECIndeterminate, "no DS and no NSEC/NSEC3 proof of its absence", nil
+	}
+
+	var nsecs []*dns.NSEC
+	var nsec3s []*dns.NSEC3
+	for key, rrset := range rrsets {
+		if result, detail := verifyRRset(rrset, sigs[key], parentKeys); result != "valid" {
+			return DNSSECBogus, fmt.Sprintf("DS denial %s: %s", result, detail), nil
+		}
+		for _, rr := range rrset {
+			switch denial := rr.(type) {
+			case *dns.NSEC:
+				nsecs = append(nsecs, denial)
+			case *dns.NSEC3:
+				nsec3s = append(nsec3s, denial)
+			}
+		}
+	}
+
+	// NSEC: the delegation's own record has NS but neither DS nor SOA (RFC 4035 5.2)
+	for _, nsec := range nsecs {
+		if dns.CanonicalName(nsec.Hdr.Name) == zone && isDelegationWithoutDS(nsec.TypeBitMap) {
+			return DNSSECInsecure, "unsigned delegation (NSEC proves no DS)", nil
+		}
+	}
+
+	// NSEC3: a matching record without DS, or an opt-out span covering the next closer name (RFC 5155 8.9)
+	for _, nsec3 := range nsec3s {
+		if nsec3.Match(zone) && isDelegationWithoutDS(nsec3.TypeBitMap) {
+			return DNSSECInsecure, "unsigned delegation (NSEC3 proves no DS)", nil
+		}
+	}
+	labels := dns.SplitDomainName(zone)
+	for i := 1; i < len(labels); i++ {
+		encloser := dns.Fqdn(strings.Join(labels[i:], "."))
+		nextCloser := dns.Fqdn(strings.Join(labels[i-1:], "."))
+		if !matchesAnyNSEC3(nsec3s, encloser) {
+			continue
+		}
+		for _, nsec3 := range nsec3s {
+			if nsec3.Flags&1 != 0 && nsec3.Cover(nextCloser) {
+				return DNSSECInsecure, "unsigned delegation (NSEC3 opt-out)", nil
+			}
+		}
+		break
+	}
+
+	return DNSSECBogus, "no DS and the NSEC/NSEC3 records do not prove its absence", nil
+}
+
+// isDelegationWithoutDS reports whether a type bitmap describes a zone cut without a DS record
+func isDelegationWithoutDS(types []uint16) bool {
+	hasNS := false
+	for _, t := range types {
+		switch t {
+		case dns.TypeDS, dns.TypeSOA:
+			return false
+		case dns.TypeNS:
+			hasNS = true
+		}
+	}
+	return hasNS
+}
+
+// matchesAnyNSEC3 reports whether one of the NSEC3 records is the hashed owner name
+func matchesAnyNSEC3(nsec3s []*dns.NSEC3, name string) bool {
+	for _, nsec3 := range nsec3s {
+		if nsec3.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyRRset checks that at least one signature over rrset verifies with one of keys
+func verifyRRset(rrset []dns.RR, sigs []*dns.RRSIG, keys []*dns.DNSKEY) (string, string) {
+	if len(sigs) == 0 {
+		return "missing", "no RRSIG returned"
+	}
+
+	detail := "no signature matches a trusted key"
+	now := time.Now()
+	for _, sig := range sigs {
+		for _, key := range keys {
+			if key.KeyTag() != sig.KeyTag || key.Algorithm != sig.Algorithm {
+				continue
+			}
+			if err := sig.Verify(key, rrset); err != nil {
+				detail = fmt.Sprintf("tag %d: %v", sig.KeyTag, err)
+				continue
+			}
+			if !sig.ValidityPeriod(now) {
+				detail = fmt.Sprintf("tag %d: signature expired or not yet valid", sig.KeyTag)
+				continue
+			}
+			return "valid", fmt.Sprintf("signed by tag %d, alg %s", sig.KeyTag, dns.AlgorithmToString[sig.Algorithm])
+		}
+	}
+	return "invalid", detail
+}
+
+// matchesDS reports whether key hashes to one of the given DS records
+func matchesDS(key *dns.DNSKEY, dsSet []*dns.DS) bool {
+	for _, ds := range dsSet {
+		if ds.KeyTag != key.KeyTag() || ds.Algorithm != key.Algorithm {
+			continue
+		}
+		computed := key.ToDS(ds.DigestType)
+		if computed != nil && strings.EqualFold(computed.Digest, ds.Digest) {
+			return true
+		}
+	}
+	return false
+}
+
+// dnskeys extracts the DNSKEY records from an RRset
+func dnskeys(rrset []dns.RR) []*dns.DNSKEY {
+	var keys []*dns.DNSKEY
+	for _, rr := range rrset {
+		if key, ok := rr.(*dns.DNSKEY); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// showResolverDNSSEC prints the DNSSEC behaviour of every configured resolver
+func showResolverDNSSEC(dnsConfig *DNSConfig) {
+	servers := uniqueDNSServers(dnsConfig)
+	if len(servers) == 0 {
+		return
+	}
+
+	display.PrintInfo("Checking DNSSEC validation on configured resolvers...")
+
+	var tableData [][]string
+	for _, server := range servers {
+		status := CheckResolverDNSSEC(server)
+
+		verdict := display.Error("NOT VALIDATING")
+		if status.Error != "" {
+			verdict = display.Warning("UNREACHABLE")
+		} else if status.Validating {
+			verdict = display.Success("VALIDATING")
+		} else if status.ADBit || status.RejectsBogus {
+			verdict = display.Warning("PARTIAL")
+		}
+
+		row := []string{
+			server,
+			yesNo(status.ADBit),
+			yesNo(status.RejectsBogus),
+			verdict,
+		}
+		tableData = append(tableData, row)
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Resolver DNSSEC Validation"
+	tableConfig.Headers = []string{"Resolver", "AD Bit", "Rejects Bogus", "Verdict"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+
+	display.PrintTable(tableConfig)
+}
+
+// ShowDNSSECChain validates the chain of trust for a user-supplied name
+func ShowDNSSECChain() error {
+	display.PrintInfo("DNSSEC Chain of Trust")
+	display.PrintSeparator()
+
+	name, err := display.ShowInput("Enter name to validate", "ietf.org")
+	if err != nil {
+		display.PrintError(fmt.Sprintf("Input error: %v", err))
+		return err
+	}
+
+	dnsConfig, err := getDNSConfig()
+	if err != nil {
+		display.PrintError(fmt.Sprintf("Failed to get DNS information: %v", err))
+		return err
+	}
+	servers := uniqueDNSServers(dnsConfig)
+	if len(servers) == 0 {
+		display.PrintWarning(utils.MsgNoDNSServers)
+		return nil
+	}
+
+	display.PrintInfo(fmt.Sprintf("Validating %s via %s...", name, servers[0]))
+	chain, err := ValidateDNSSECChain(servers[0], name, dns.TypeA)
+	if err != nil {
+		display.PrintError(utils.GetUserFriendlyMessage(err))
+		return err
+	}
+
+	var tableData [][]string
+	for _, link := range chain.Links {
+		row := []string{
+			link.Zone,
+			link.DS,
+			link.DNSKEY,
+			link.RRSIG,
+			dnssecStatusColor(link.Status),
+			link.Detail,
+		}
+		tableData = append(tableData, row)
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = fmt.Sprintf("Chain of Trust for %s", chain.Name)
+	tableConfig.Headers = []string{"Zone", "DS", "DNSKEY", "RRSIG", "Status", "Detail"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+
+	display.PrintTable(tableConfig)
+
+	switch chain.Status {
+	case DNSSECSecure:
+		display.PrintSuccess(fmt.Sprintf("%s is DNSSEC secure", chain.Name))
+	case DNSSECInsecure:
+		display.PrintWarning(fmt.Sprintf("%s is not signed (insecure delegation)", chain.Name))
+	case DNSSECBogus:
+		display.PrintError(fmt.Sprintf("%s failed DNSSEC validation", chain.Name))
+	default:
+		display.PrintWarning(fmt.Sprintf("DNSSEC status of %s could not be determined", chain.Name))
+	}
+
+	return nil
+}
+
+// dnssecStatusColor colors a DNSSEC status for display
+func dnssecStatusColor(status string) string {
+	switch status {
+	case DNSSECSecure:
+		return display.Success(status)
+	case DNSSECBogus:
+		return display.Error(status)
+	default:
+		return display.Warning(status)
+	}
+}
+
+// yesNo formats a boolean for table output
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
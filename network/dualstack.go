@@ -0,0 +1,367 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+// Happy Eyeballs v2 timers (RFC 8305 section 8)
+const (
+	resolutionDelay        = 50 * time.Millisecond
+	connectionAttemptDelay = 250 * time.Millisecond
+)
+
+// ConnectAttempt is one TCP connection attempt
+type ConnectAttempt struct {
+	Family    string        `json:"family"`
+	Address   string        `json:"address"`
+	StartedAt time.Duration `json:"started_at"` // offset from the start of the race
+	Latency   time.Duration `json:"latency"`    // connect time of this attempt
+	Connected bool          `json:"connected"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// HappyEyeballsResult holds the outcome of an RFC 8305 connection race
+type HappyEyeballsResult struct {
+	Host       string           `json:"host"`
+	Port       string           `json:"port"`
+	Attempts   []ConnectAttempt `json:"attempts"`
+	Winner     string           `json:"winner"` // family of the first established connection
+	WinnerAddr string           `json:"winner_addr"`
+	Elapsed    time.Duration    `json:"elapsed"`
+}
+
+// DualStackReport holds the IPv6 readiness checks and the connection race
+type DualStackReport struct {
+	Target        string               `json:"target"`
+	GlobalIPv6    []string             `json:"global_ipv6"`
+	IPv6Gateway   string               `json:"ipv6_gateway"`
+	IPv4Addresses []string             `json:"ipv4_addresses"` // A records of the target
+	IPv6Addresses []string             `json:"ipv6_addresses"` // AAAA records of the target
+	IPv4Connect   *ConnectAttempt      `json:"ipv4_connect"`   // unstaggered per-family connect
+	IPv6Connect   *ConnectAttempt      `json:"ipv6_connect"`
+	Ready         bool                 `json:"ready"`
+	Race          *HappyEyeballsResult `json:"race"`
+}
+
+// CheckDualStack runs the IPv6 readiness checks and a Happy Eyeballs race against host:port
+func CheckDualStack(host, port string) (*DualStackReport, error) {
+	report := &DualStackReport{Target: net.JoinHostPort(host, port)}
+
+	// Global IPv6 address
+	localIPs, err := GetLocalIPs()
+	if err != nil {
+		return nil, err
+	}
+	for _, ipInfo := range localIPs {
+		for _, classification := range ipInfo.Classifications {
+			if classification.Family == "IPv6" && classification.Global {
+				report.GlobalIPv6 = append(report.GlobalIPv6, classification.Address)
+			}
+		}
+	}
+
+	// IPv6 default route
+	if gateway, err := GetDefaultGateway("IPv6"); err == nil {
+		report.IPv6Gateway = gateway.Gateway
+	}
+
+	// A and AAAA resolution
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DNSQueryTimeout)
+	defer cancel()
+	report.IPv4Addresses, report.IPv6Addresses = resolveFamilies(ctx, host)
+
+	// Connect to the first address of each family at the same time to compare latency
+	var wg sync.WaitGroup
+	for _, family := range []string{"IPv4", "IPv6"} {
+		addrs := report.IPv4Addresses
+		if family == "IPv6" {
+			addrs = report.IPv6Addresses
+		}
+		if len(addrs) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(family, addr string) {
+			defer wg.Done()
+			attempt := dialAttempt(context.Background(), family, addr, port, 0)
+			if family == "IPv4" {
+				report.IPv4Connect = &attempt
+			} else {
+				report.IPv6Connect = &attempt
+			}
+		}(family, addrs[0])
+	}
+	wg.Wait()
+
+	report.Ready = len(report.GlobalIPv6) > 0 && report.IPv6Gateway != "" &&
+		len(report.IPv6Addresses) > 0 && report.IPv6Connect != nil && report.IPv6Connect.Connected
+
+	report.Race = HappyEyeballs(host, port)
+
+	return report, nil
+}
+
+// resolveFamilies looks up A and AAAA records concurrently
+func resolveFamilies(ctx context.Context, host string) ([]string, []string) {
+	var ipv4, ipv6 []string
+	var wg sync.WaitGroup
+	for _, network := range []string{"ip4", "ip6"} {
+		wg.Add(1)
+		go func(network string) {
+			defer wg.Done()
+			ips, err := net.DefaultResolver.LookupIP(ctx, network, host)
+			if err != nil {
+				return
+			}
+			var addrs []string
+			for _, ip := range ips {
+				addrs = append(addrs, ip.String())
+			}
+			if network == "ip4" {
+				ipv4 = addrs
+			} else {
+				ipv6 = addrs
+			}
+		}(network)
+	}
+	wg.Wait()
+	return ipv4, ipv6
+}
+
+// HappyEyeballs races TCP connections to host:port the way RFC 8305 clients do:
+// AAAA first, addresses interleaved by family and a new attempt every 250 ms until one connects
+func HappyEyeballs(host, port string) *HappyEyeballsResult {
+	result := &HappyEyeballsResult{Host: host, Port: port}
+	start := time.Now()
+
+	addrs := resolveHappyEyeballs(host)
+	if len(addrs) == 0 {
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.ConnectionTimeout)
+	defer cancel()
+
+	attempts := make([]ConnectAttempt, len(addrs))
+	done := make(chan int, len(addrs))
+	started := 0
+
+	launch := func() {
+		i := started
+		started++
+		offset := time.Since(start)
+		go func() {
+			attempts[i] = dialAttempt(ctx, ipFamily(addrs[i]), addrs[i], port, offset)
+			done <- i
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(connectionAttemptDelay)
+	defer timer.Stop()
+
+	for finished := 0; finished < started; {
+		select {
+		case i := <-done:
+			finished++
+			if attempts[i].Connected && result.Winner == "" {
+				result.Winner = attempts[i].Family
+				result.WinnerAddr = attempts[i].Address
+				result.Elapsed = time.Since(start)
+				// Abandon the attempts still running
+				cancel()
+				continue
+			}
+			if result.Winner != "" && !attempts[i].Connected {
+				attempts[i].Error = "cancelled, lost the race"
+			}
+			// A failed attempt starts the next one immediately
+			if result.Winner == "" && started < len(addrs) {
+				launch()
+				timer.Reset(connectionAttemptDelay)
+			}
+		case <-timer.C:
+			if result.Winner == "" && started < len(addrs) {
+				launch()
+				timer.Reset(connectionAttemptDelay)
+			}
+		}
+	}
+
+	result.Attempts = attempts[:started]
+	return result
+}
+
+// resolveHappyEyeballs resolves host and orders the addresses IPv6 first, alternating families
+func resolveHappyEyeballs(host string) []string {
+	if net.ParseIP(host) != nil {
+		return []string{host}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DNSQueryTimeout)
+	defer cancel()
+
+	type answer struct {
+		network string
+		addrs   []string
+	}
+	answers := make(chan answer, 2)
+	for _, network := range []string{"ip6", "ip4"} {
+		go func(network string) {
+			var addrs []string
+			if ips, err := net.DefaultResolver.LookupIP(ctx, network, host); err == nil {
+				for _, ip := range ips {
+					addrs = append(addrs, ip.String())
+				}
+			}
+			answers <- answer{network, addrs}
+		}(network)
+	}
+
+	// When A arrives first, wait up to the resolution delay for AAAA (RFC 8305 section 3)
+	var ipv4, ipv6 []string
+	first := <-answers
+	if first.network == "ip6" {
+		ipv6 = first.addrs
+		ipv4 = (<-answers).addrs
+	} else {
+		ipv4 = first.addrs
+		select {
+		case second := <-answers:
+			ipv6 = second.addrs
+		case <-time.After(resolutionDelay):
+		}
+	}
+
+	var ordered []string
+	for i := 0; i < len(ipv4) || i < len(ipv6); i++ {
+		if i < len(ipv6) {
+			ordered = append(ordered, ipv6[i])
+		}
+		if i < len(ipv4) {
+			ordered = append(ordered, ipv4[i])
+		}
+	}
+	return ordered
+}
+
+// dialAttempt opens and closes one TCP connection
+func dialAttempt(ctx context.Context, family, addr, port string, offset time.Duration) ConnectAttempt {
+	attempt := ConnectAttempt{Family: family, Address: addr, StartedAt: offset}
+
+	network := "tcp4"
+	if family == "IPv6" {
+		network = "tcp6"
+	}
+	dialer := &net.Dialer{Timeout: utils.ConnectionTimeout}
+
+	begin := time.Now()
+	conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+	attempt.Latency = time.Since(begin)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	conn.Close()
+	attempt.Connected = true
+	return attempt
+}
+
+// ShowDualStack displays IPv6 readiness and the Happy Eyeballs race for host:port
+func ShowDualStack(host, port string) error {
+	display.PrintInfo(fmt.Sprintf("Checking dual-stack readiness against %s...", net.JoinHostPort(host, port)))
+
+	report, err := CheckDualStack(host, port)
+	if err != nil {
+		display.PrintError(fmt.Sprintf("Dual-stack check failed: %v", err))
+		return err
+	}
+
+	printDualStackChecks(report)
+
+	race := report.Race
+	if len(race.Attempts) == 0 {
+		display.PrintWarning("No addresses to race")
+		return nil
+	}
+
+	var tableData [][]string
+	for _, attempt := range race.Attempts {
+		result := display.Success("connected")
+		if !attempt.Connected {
+			result = display.Error(utils.TruncateString(attempt.Error, 40))
+		}
+		row := []string{
+			attempt.Family,
+			attempt.Address,
+			utils.FormatDuration(attempt.StartedAt),
+			utils.FormatDuration(attempt.Latency),
+			result,
+		}
+		tableData = append(tableData, row)
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Happy Eyeballs Race (RFC 8305)"
+	tableConfig.Headers = []string{"Family", "Address", "Started At", "Connect Time", "Result"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+
+	display.PrintTable(tableConfig)
+
+	if race.Winner == "" {
+		display.PrintError("No connection could be established")
+	} else {
+		display.PrintSuccess(fmt.Sprintf("%s wins via %s after %s", race.Winner, race.WinnerAddr, utils.FormatDuration(race.Elapsed)))
+	}
+
+	return nil
+}
+
+// printDualStackChecks prints the readiness checklist
+func printDualStackChecks(report *DualStackReport) {
+	check := func(ok bool, label, detail string) {
+		if ok {
+			display.PrintSuccess(fmt.Sprintf("%s: %s", label, detail))
+		} else {
+			display.PrintWarning(fmt.Sprintf("%s: %s", label, detail))
+		}
+	}
+
+	check(len(report.GlobalIPv6) > 0, "Global IPv6 address", valueOrDash(joinLimited(report.GlobalIPv6, 3)))
+	check(report.IPv6Gateway != "", "IPv6 default route", valueOrDash(report.IPv6Gateway))
+	check(len(report.IPv6Addresses) > 0, "AAAA resolution", valueOrDash(joinLimited(report.IPv6Addresses, 3)))
+
+	for _, attempt := range []*ConnectAttempt{report.IPv4Connect, report.IPv6Connect} {
+		if attempt == nil {
+			continue
+		}
+		detail := fmt.Sprintf("%s in %s", attempt.Address, utils.FormatDuration(attempt.Latency))
+		if !attempt.Connected {
+			detail = fmt.Sprintf("%s failed: %s", attempt.Address, utils.TruncateString(attempt.Error, 50))
+		}
+		check(attempt.Connected, attempt.Family+" connect", detail)
+	}
+
+	if report.Ready {
+		display.PrintSuccess("IPv6 ready")
+	} else {
+		display.PrintWarning("Not IPv6 ready - connections fall back to IPv4")
+	}
+}
+
+// joinLimited joins up to limit values, noting how many were left out
+func joinLimited(values []string, limit int) string {
+	if len(values) <= limit {
+		return strings.Join(values, ", ")
+	}
+	return fmt.Sprintf("%s (+%d more)", strings.Join(values[:limit], ", "), len(values)-limit)
+}
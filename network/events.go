@@ -0,0 +1,263 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+// Event types reported by MonitorEvents; the part before the dash is the category
+const (
+	EventLinkAdded    = "link-added"
+	EventLinkRemoved  = "link-removed"
+	EventLinkUp       = "link-up"
+	EventLinkDown     = "link-down"
+	EventLinkChanged  = "link-changed" // set up or down without a carrier, renamed or new MTU
+	EventAddrAdded    = "addr-added"
+	EventAddrRemoved  = "addr-removed"
+	EventRouteAdded   = "route-added"
+	EventRouteRemoved = "route-removed"
+	EventNeighChanged = "neigh-changed" // new entry or new NUD state
+	EventNeighRemoved = "neigh-removed"
+)
+
+// EventCategories are the values accepted by EventOptions.Types
+var EventCategories = []string{"link", "addr", "route", "neigh"}
+
+// a link going up or down this often within flapWindow is reported as flapping
+const (
+	flapWindow    = time.Minute
+	flapThreshold = 4
+)
+
+// events waiting for the hook and webhook; beyond this they are dropped rather than stalling the socket
+const notifyQueueSize = 256
+
+// NetworkEvent is one kernel notification about a link, address, route or neighbour
+type NetworkEvent struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	Interface string    `json:"interface,omitempty"`
+	Index     int       `json:"index,omitempty"`
+	Family    string    `json:"family,omitempty"`
+	Address   string    `json:"address,omitempty"` // CIDR of an address, destination of a route or neighbour
+	Gateway   string    `json:"gateway,omitempty"`
+	LLAddr    string    `json:"lladdr,omitempty"` // MAC of a neighbour
+	State     string    `json:"state,omitempty"`  // operstate of a link, NUD state of a neighbour
+	Detail    string    `json:"detail,omitempty"` // flags, protocol, table, MTU...
+
+	// Up/down transitions of the link within the last minute
+	Transitions int `json:"transitions,omitempty"`
+}
+
+// Category returns link, addr, route or neigh
+func (e NetworkEvent) Category() string {
+	category, _, _ := strings.Cut(e.Type, "-")
+	return category
+}
+
+// EventOptions selects and routes the events of MonitorEvents
+type EventOptions struct {
+	Types      []string // categories to report, all when empty
+	Interfaces []string // interfaces to report, all when empty
+	Stream     bool     // one JSON object per line instead of text
+	Hook       string   // shell command run for every event
+	Webhook    string   // URL that receives every event as a JSON POST
+}
+
+// MonitorEvents subscribes to the kernel's rtnetlink notifications and reports
+// every link, address, route and neighbour change until interrupted
+func MonitorEvents(opts EventOptions) error {
+	types := make(map[string]bool)
+	for _, t := range opts.Types {
+		valid := false
+		for _, category := range EventCategories {
+			valid = valid || t == category
+		}
+		if !valid {
+			return fmt.Errorf("unknown event type %q, expected one of %s", t, strings.Join(EventCategories, ", "))
+		}
+		types[t] = true
+	}
+	interfaces := make(map[string]bool)
+	for _, name := range opts.Interfaces {
+		interfaces[name] = true
+	}
+
+	// Diagnostics and hook output must not interleave with the JSON stream
+	var diagnostics io.Writer = os.Stdout
+	if opts.Stream {
+		diagnostics = os.Stderr
+	}
+	warn := func(msg string) { display.FprintWarning(diagnostics, msg) }
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithCancel(interrupted)
+	defer cancel()
+
+	// One worker runs hooks and webhooks so they see the events in kernel order
+	notifications := make(chan NetworkEvent, notifyQueueSize)
+	var worker sync.WaitGroup
+	if opts.Hook != "" || opts.Webhook != "" {
+		worker.Add(1)
+		go func() {
+			defer worker.Done()
+			for event := range notifications {
+				if ctx.Err() == nil {
+					notifyEvent(event, opts.Hook, opts.Webhook, diagnostics)
+				}
+			}
+		}()
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	transitions := make(map[string][]time.Time)
+	var outputErr error
+
+	handle := func(event NetworkEvent) {
+		if outputErr != nil {
+			return
+		}
+		if len(types) > 0 && !types[event.Category()] {
+			return
+		}
+		if len(interfaces) > 0 && !interfaces[event.Interface] {
+			return
+		}
+
+		if event.Type == EventLinkUp || event.Type == EventLinkDown {
+			recent := []time.Time{event.Time}
+			for _, t := range transitions[event.Interface] {
+				if event.Time.Sub(t) < flapWindow {
+					recent = append(recent, t)
+				}
+			}
+			transitions[event.Interface] = recent
+			event.Transitions = len(recent)
+		}
+
+		var err error
+		if opts.Stream {
+			err = encoder.Encode(event)
+		} else {
+			err = printEvent(event)
+		}
+		if err != nil {
+			// Nobody is reading any more, e.g. the pipe was closed
+			outputErr = utils.WrapError(err, "Failed to write event", utils.ErrorTypeUnknown)
+			cancel()
+			return
+		}
+
+		if opts.Hook != "" || opts.Webhook != "" {
+			select {
+			case notifications <- event:
+			default:
+				warn(fmt.Sprintf("Hook queue full, %s event on %s not delivered", event.Type, event.Interface))
+			}
+		}
+	}
+
+	if !opts.Stream {
+		display.PrintInfo("Watching link, address, route and neighbour changes (Ctrl-C to stop)...")
+	}
+	err := watchNetlinkEvents(ctx, handle, warn)
+	close(notifications)
+	worker.Wait()
+	if outputErr != nil {
+		return outputErr
+	}
+	return err
+}
+
+// printEvent writes one event as a timestamped line
+func printEvent(event NetworkEvent) error {
+	kind := fmt.Sprintf("%-13s", event.Type)
+	switch event.Type {
+	case EventLinkUp, EventLinkAdded, EventAddrAdded, EventRouteAdded:
+		kind = display.Success(kind)
+	case EventLinkDown, EventLinkRemoved, EventAddrRemoved, EventRouteRemoved:
+		kind = display.Error(kind)
+	case EventNeighChanged, EventNeighRemoved:
+		kind = display.Muted(kind)
+	default:
+		kind = display.Warning(kind)
+	}
+
+	var parts []string
+	if event.Interface != "" {
+		parts = append(parts, display.Info(event.Interface))
+	}
+	if event.Address != "" {
+		parts = append(parts, display.IP(event.Address))
+	}
+	if event.Gateway != "" {
+		parts = append(parts, "via "+display.IP(event.Gateway))
+	}
+	if event.LLAddr != "" {
+		parts = append(parts, "lladdr "+event.LLAddr)
+	}
+	if event.State != "" {
+		parts = append(parts, event.State)
+	}
+	if event.Detail != "" {
+		parts = append(parts, display.Secondary(event.Detail))
+	}
+
+	line := fmt.Sprintf("%s  %s  %s", display.Muted(event.Time.Format("15:04:05.000")), kind, strings.Join(parts, "  "))
+	if event.Transitions >= flapThreshold {
+		line += "  " + display.Warning(fmt.Sprintf("flapping: %d transitions in the last minute", event.Transitions))
+	}
+	_, err := fmt.Println(line)
+	return err
+}
+
+// notifyEvent runs the hook command and posts to the webhook; hook output and
+// failures are written to out
+func notifyEvent(event NetworkEvent, hook, webhook string, out io.Writer) {
+	if hook != "" {
+		if err := runEventHook(event, hook, out); err != nil {
+			display.FprintError(out, fmt.Sprintf("Hook failed: %v", err))
+		}
+	}
+	if webhook != "" {
+		if err := postJSONWebhook(event, webhook); err != nil {
+			display.FprintError(out, fmt.Sprintf("Webhook failed: %v", err))
+		}
+	}
+}
+
+// runEventHook runs hook through the shell with the event in its environment
+func runEventHook(event NetworkEvent, hook string, stdout io.Writer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), utils.CommandTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook)
+	cmd.Env = append(os.Environ(),
+		"NETINFO_EVENT_TYPE="+event.Type,
+		"NETINFO_EVENT_INTERFACE="+event.Interface,
+		"NETINFO_EVENT_ADDRESS="+event.Address,
+		"NETINFO_EVENT_STATE="+event.State,
+		"NETINFO_EVENT_TIME="+event.Time.Format(time.RFC3339Nano),
+		"NETINFO_EVENT_JSON="+string(data),
+	)
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
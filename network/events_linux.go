@@ -0,0 +1,426 @@
+//go:build linux
+
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+	"unsafe"
+
+	"netinfo/utils"
+
+	"golang.org/x/sys/unix"
+)
+
+// rtnetlink multicast groups MonitorEvents listens to
+const eventGroups = unix.RTMGRP_LINK |
+	unix.RTMGRP_IPV4_IFADDR | unix.RTMGRP_IPV6_IFADDR |
+	unix.RTMGRP_IPV4_ROUTE | unix.RTMGRP_IPV6_ROUTE |
+	unix.RTMGRP_NEIGH
+
+// RFC 2863 operational states as IFLA_OPERSTATE reports them
+var operStates = map[uint8]string{
+	0: "unknown",
+	1: "notpresent",
+	2: "down",
+	3: "lowerlayerdown",
+	4: "testing",
+	5: "dormant",
+	6: "up",
+}
+
+// Neighbour (NUD) states from linux/neighbour.h
+var nudStates = []struct {
+	bit  uint16
+	name string
+}{
+	{unix.NUD_INCOMPLETE, "incomplete"},
+	{unix.NUD_REACHABLE, "reachable"},
+	{unix.NUD_STALE, "stale"},
+	{unix.NUD_DELAY, "delay"},
+	{unix.NUD_PROBE, "probe"},
+	{unix.NUD_FAILED, "failed"},
+	{unix.NUD_NOARP, "noarp"},
+	{unix.NUD_PERMANENT, "permanent"},
+}
+
+// Route origins from linux/rtnetlink.h
+var routeProtocols = map[uint8]string{
+	unix.RTPROT_REDIRECT: "redirect",
+	unix.RTPROT_KERNEL:   "kernel",
+	unix.RTPROT_BOOT:     "boot",
+	unix.RTPROT_STATIC:   "static",
+	unix.RTPROT_RA:       "ra",
+	unix.RTPROT_DHCP:     "dhcp",
+}
+
+// linkState is what is known about a link between two notifications
+type linkState struct {
+	name    string
+	adminUp bool
+	running bool
+	mtu     uint32
+}
+
+// watchNetlinkEvents reads rtnetlink notifications until ctx is done and hands every event to handle
+func watchNetlinkEvents(ctx context.Context, handle func(NetworkEvent), warn func(string)) error {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+	if err != nil {
+		return utils.WrapError(err, "Failed to open netlink socket", utils.ErrorTypePermission)
+	}
+	defer unix.Close(fd)
+
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: eventGroups}); err != nil {
+		return utils.WrapError(err, "Failed to subscribe to netlink notifications", utils.ErrorTypePermission)
+	}
+	// Wake up every second to notice Ctrl-C
+	timeout := unix.NsecToTimeval(time.Second.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		return utils.WrapError(err, "Failed to configure netlink socket", utils.ErrorTypeNetwork)
+	}
+
+	links := currentLinks()
+	buf := make([]byte, 1<<16)
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		switch err {
+		case nil:
+		case unix.EAGAIN, unix.EINTR:
+			continue
+		case unix.ENOBUFS:
+			// The kernel dropped notifications while we were busy; the link states may be stale
+			warn("Receive buffer overflowed, some events were lost")
+			links = currentLinks()
+			continue
+		default:
+			return utils.WrapError(err, "Failed to read netlink notifications", utils.ErrorTypeNetwork)
+		}
+
+		now := time.Now()
+		for _, msg := range splitNetlinkMessages(buf[:n]) {
+			if event, ok := decodeNetlinkEvent(msg, links); ok {
+				event.Time = now
+				handle(event)
+			}
+		}
+	}
+}
+
+// currentLinks snapshots the links so the first notification of each can be compared
+func currentLinks() map[int]*linkState {
+	links := make(map[int]*linkState)
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return links
+	}
+	for _, iface := range interfaces {
+		running := iface.Flags&net.FlagRunning != 0 && iface.Flags&net.FlagUp != 0
+		if oper := readSysfs(iface.Name, "operstate"); oper != "" && oper != "unknown" {
+			running = oper == "up"
+		}
+		links[iface.Index] = &linkState{
+			name:    iface.Name,
+			adminUp: iface.Flags&net.FlagUp != 0,
+			running: running,
+			mtu:     uint32(iface.MTU),
+		}
+	}
+	return links
+}
+
+type netlinkMessage struct {
+	kind uint16
+	data []byte // payload after the nlmsghdr
+}
+
+// splitNetlinkMessages cuts a datagram into its messages
+func splitNetlinkMessages(buf []byte) []netlinkMessage {
+	var messages []netlinkMessage
+	for len(buf) >= unix.NLMSG_HDRLEN {
+		length := int(binary.NativeEndian.Uint32(buf[0:4]))
+		if length < unix.NLMSG_HDRLEN || length > len(buf) {
+			break
+		}
+		messages = append(messages, netlinkMessage{
+			kind: binary.NativeEndian.Uint16(buf[4:6]),
+			data: buf[unix.NLMSG_HDRLEN:length],
+		})
+		buf = buf[min(netlinkAlign(length), len(buf)):]
+	}
+	return messages
+}
+
+// parseAttributes splits the rtattr list that follows a fixed header
+func parseAttributes(data []byte) map[uint16][]byte {
+	attrs := make(map[uint16][]byte)
+	for len(data) >= unix.SizeofRtAttr {
+		length := int(binary.NativeEndian.Uint16(data[0:2]))
+		kind := binary.NativeEndian.Uint16(data[2:4])
+		if length < unix.SizeofRtAttr || length > len(data) {
+			break
+		}
+		attrs[kind] = data[unix.SizeofRtAttr:length]
+		data = data[min(netlinkAlign(length), len(data)):]
+	}
+	return attrs
+}
+
+func netlinkAlign(length int) int {
+	return (length + unix.NLMSG_ALIGNTO - 1) &^ (unix.NLMSG_ALIGNTO - 1)
+}
+
+// decodeNetlinkEvent turns one notification into an event; ok is false for
+// messages that change nothing worth reporting
+func decodeNetlinkEvent(msg netlinkMessage, links map[int]*linkState) (NetworkEvent, bool) {
+	switch msg.kind {
+	case unix.RTM_NEWLINK, unix.RTM_DELLINK:
+		return decodeLinkEvent(msg, links)
+	case unix.RTM_NEWADDR, unix.RTM_DELADDR:
+		return decodeAddrEvent(msg, links)
+	case unix.RTM_NEWROUTE, unix.RTM_DELROUTE:
+		return decodeRouteEvent(msg, links)
+	case unix.RTM_NEWNEIGH, unix.RTM_DELNEIGH:
+		return decodeNeighEvent(msg, links)
+	}
+	return NetworkEvent{}, false
+}
+
+// decodeLinkEvent reports links appearing, disappearing, going up or down, renamed or with a new MTU.
+// The kernel also sends RTM_NEWLINK for changes not tracked here; those are dropped.
+func decodeLinkEvent(msg netlinkMessage, links map[int]*linkState) (NetworkEvent, bool) {
+	if len(msg.data) < unix.SizeofIfInfomsg {
+		return NetworkEvent{}, false
+	}
+	info := (*unix.IfInfomsg)(unsafe.Pointer(&msg.data[0]))
+	attrs := parseAttributes(msg.data[unix.SizeofIfInfomsg:])
+
+	index := int(info.Index)
+	event := NetworkEvent{Index: index, Interface: cString(attrs[unix.IFLA_IFNAME])}
+
+	if msg.kind == unix.RTM_DELLINK {
+		delete(links, index)
+		event.Type = EventLinkRemoved
+		return event, true
+	}
+
+	oper := "unknown"
+	if value, ok := attrs[unix.IFLA_OPERSTATE]; ok && len(value) > 0 {
+		oper = operStates[value[0]]
+	}
+	// Loopback and tun devices stay in "unknown"; fall back to the flags for them
+	running := oper == "up"
+	if oper == "unknown" {
+		running = info.Flags&unix.IFF_UP != 0 && info.Flags&unix.IFF_LOWER_UP != 0
+	}
+	var mtu uint32
+	if value, ok := attrs[unix.IFLA_MTU]; ok && len(value) >= 4 {
+		mtu = binary.NativeEndian.Uint32(value)
+	}
+	adminUp := info.Flags&unix.IFF_UP != 0
+	event.State = oper
+
+	previous, known := links[index]
+	links[index] = &linkState{name: event.Interface, adminUp: adminUp, running: running, mtu: mtu}
+
+	switch {
+	case !known:
+		event.Type = EventLinkAdded
+	case previous.running != running && running:
+		event.Type = EventLinkUp
+	case previous.running != running:
+		event.Type = EventLinkDown
+		if !adminUp {
+			event.Detail = "administratively down"
+		} else if info.Flags&unix.IFF_LOWER_UP == 0 {
+			event.Detail = "no carrier"
+		}
+	case previous.adminUp != adminUp:
+		// Set up or down while the link could not run anyway, e.g. without carrier
+		event.Type = EventLinkChanged
+		event.Detail = "administratively down"
+		if adminUp {
+			event.Detail = "administratively up"
+		}
+	case previous.name != event.Interface:
+		event.Type = EventLinkChanged
+		event.Detail = "renamed from " + previous.name
+	case previous.mtu != mtu && mtu != 0:
+		event.Type = EventLinkChanged
+		event.Detail = fmt.Sprintf("mtu %d -> %d", previous.mtu, mtu)
+	default:
+		return NetworkEvent{}, false
+	}
+	return event, true
+}
+
+// decodeAddrEvent reports addresses being added or removed, with their flags
+func decodeAddrEvent(msg netlinkMessage, links map[int]*linkState) (NetworkEvent, bool) {
+	if len(msg.data) < unix.SizeofIfAddrmsg {
+		return NetworkEvent{}, false
+	}
+	info := (*unix.IfAddrmsg)(unsafe.Pointer(&msg.data[0]))
+	attrs := parseAttributes(msg.data[unix.SizeofIfAddrmsg:])
+
+	// IFA_LOCAL is the address itself; IFA_ADDRESS is the peer on point-to-point links
+	address := attrs[unix.IFA_LOCAL]
+	if address == nil {
+		address = attrs[unix.IFA_ADDRESS]
+	}
+	if address == nil {
+		return NetworkEvent{}, false
+	}
+
+	event := NetworkEvent{
+		Type:      EventAddrAdded,
+		Index:     int(info.Index),
+		Interface: linkName(links, int(info.Index)),
+		Family:    familyName(info.Family),
+		Address:   fmt.Sprintf("%s/%d", net.IP(address), info.Prefixlen),
+	}
+	if msg.kind == unix.RTM_DELADDR {
+		event.Type = EventAddrRemoved
+	}
+
+	// IFA_FLAGS carries the full 32-bit flags, the header only the low 8
+	flags := uint32(info.Flags)
+	if value, ok := attrs[unix.IFA_FLAGS]; ok && len(value) >= 4 {
+		flags = binary.NativeEndian.Uint32(value)
+	}
+	var names []string
+	for _, f := range []struct {
+		bit  uint32
+		name string
+	}{
+		{unix.IFA_F_SECONDARY, AddrFlagSecondary},
+		{unix.IFA_F_TENTATIVE, AddrFlagTentative},
+		{unix.IFA_F_DADFAILED, AddrFlagDADFailed},
+		{unix.IFA_F_DEPRECATED, AddrFlagDeprecated},
+		{unix.IFA_F_TEMPORARY, AddrFlagTemporary},
+	} {
+		if flags&f.bit != 0 {
+			names = append(names, f.name)
+		}
+	}
+	event.Detail = strings.Join(names, ", ")
+	return event, true
+}
+
+// decodeRouteEvent reports routes being added or removed. Routes of the local table
+// follow every address change and are left out, as are cached clones.
+func decodeRouteEvent(msg netlinkMessage, links map[int]*linkState) (NetworkEvent, bool) {
+	if len(msg.data) < unix.SizeofRtMsg {
+		return NetworkEvent{}, false
+	}
+	info := (*unix.RtMsg)(unsafe.Pointer(&msg.data[0]))
+	attrs := parseAttributes(msg.data[unix.SizeofRtMsg:])
+
+	table := uint32(info.Table)
+	if value, ok := attrs[unix.RTA_TABLE]; ok && len(value) >= 4 {
+		table = binary.NativeEndian.Uint32(value)
+	}
+	if table == unix.RT_TABLE_LOCAL || info.Flags&unix.RTM_F_CLONED != 0 {
+		return NetworkEvent{}, false
+	}
+
+	event := NetworkEvent{
+		Type:    EventRouteAdded,
+		Family:  familyName(info.Family),
+		Address: "default",
+	}
+	if msg.kind == unix.RTM_DELROUTE {
+		event.Type = EventRouteRemoved
+	}
+	if dst, ok := attrs[unix.RTA_DST]; ok {
+		event.Address = fmt.Sprintf("%s/%d", net.IP(dst), info.Dst_len)
+	}
+	if gateway, ok := attrs[unix.RTA_GATEWAY]; ok {
+		event.Gateway = net.IP(gateway).String()
+	}
+	if oif, ok := attrs[unix.RTA_OIF]; ok && len(oif) >= 4 {
+		event.Index = int(binary.NativeEndian.Uint32(oif))
+		event.Interface = linkName(links, event.Index)
+	}
+
+	var details []string
+	if protocol, ok := routeProtocols[info.Protocol]; ok {
+		details = append(details, "proto "+protocol)
+	}
+	if metric, ok := attrs[unix.RTA_PRIORITY]; ok && len(metric) >= 4 {
+		details = append(details, fmt.Sprintf("metric %d", binary.NativeEndian.Uint32(metric)))
+	}
+	if table != unix.RT_TABLE_MAIN {
+		details = append(details, fmt.Sprintf("table %d", table))
+	}
+	event.Detail = strings.Join(details, ", ")
+	return event, true
+}
+
+// decodeNeighEvent reports ARP and NDP entries appearing, changing state or going away
+func decodeNeighEvent(msg netlinkMessage, links map[int]*linkState) (NetworkEvent, bool) {
+	if len(msg.data) < unix.SizeofNdMsg {
+		return NetworkEvent{}, false
+	}
+	info := (*unix.NdMsg)(unsafe.Pointer(&msg.data[0]))
+	attrs := parseAttributes(msg.data[unix.SizeofNdMsg:])
+
+	dst, ok := attrs[unix.NDA_DST]
+	if !ok {
+		return NetworkEvent{}, false
+	}
+	event := NetworkEvent{
+		Type:      EventNeighChanged,
+		Index:     int(info.Ifindex),
+		Interface: linkName(links, int(info.Ifindex)),
+		Family:    familyName(info.Family),
+		Address:   net.IP(dst).String(),
+	}
+	if msg.kind == unix.RTM_DELNEIGH {
+		event.Type = EventNeighRemoved
+	}
+	if lladdr, ok := attrs[unix.NDA_LLADDR]; ok && len(lladdr) > 0 {
+		event.LLAddr = net.HardwareAddr(lladdr).String()
+	}
+
+	var states []string
+	for _, s := range nudStates {
+		if info.State&s.bit != 0 {
+			states = append(states, s.name)
+		}
+	}
+	event.State = strings.Join(states, ",")
+	return event, true
+}
+
+// linkName returns the name of the link with index, looking it up when it is new
+func linkName(links map[int]*linkState, index int) string {
+	if link, ok := links[index]; ok {
+		return link.name
+	}
+	if iface, err := net.InterfaceByIndex(index); err == nil {
+		return iface.Name
+	}
+	return ""
+}
+
+func familyName(family uint8) string {
+	switch family {
+	case unix.AF_INET:
+		return "IPv4"
+	case unix.AF_INET6:
+		return "IPv6"
+	}
+	return ""
+}
+
+// cString converts a NUL-terminated attribute to a string
+func cString(value []byte) string {
+	name, _, _ := strings.Cut(string(value), "\x00")
+	return name
+}
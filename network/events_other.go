@@ -0,0 +1,13 @@
+//go:build !linux
+
+package network
+
+import (
+	"context"
+	"fmt"
+)
+
+// watchNetlinkEvents needs rtnetlink, which only Linux has
+func watchNetlinkEvents(ctx context.Context, handle func(NetworkEvent), warn func(string)) error {
+	return fmt.Errorf("event monitoring is only available on Linux")
+}
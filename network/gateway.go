@@ -17,6 +17,7 @@ import (
 type GatewayInfo struct {
 	Interface string `json:"interface"`
 	Gateway   string `json:"gateway"`
+	Name      string `json:"name,omitempty"` // PTR name of the gateway
 	IPVersion string `json:"ip_version"`
 	Metric    int    `json:"metric"`
 	Source    string `json:"source"`
@@ -53,6 +54,11 @@ func ShowGatewayInformation() error {
 		return err
 	}
 	
+	// Add PTR names for gateways
+	if ResolveNamesEnabled() {
+		enrichGatewayNames(gatewayConfig)
+	}
+	
 	// Display default gateways
 	if gatewayConfig.DefaultIPv4 != nil || gatewayConfig.DefaultIPv6 != nil {
 		display.PrintSuccess("Found default gateway information")
@@ -63,7 +69,7 @@ func ShowGatewayInformation() error {
 			row := []string{
 				"Default IPv4",
 				gatewayConfig.DefaultIPv4.Interface,
-				gatewayConfig.DefaultIPv4.Gateway,
+				withName(gatewayConfig.DefaultIPv4.Gateway, gatewayConfig.DefaultIPv4.Name),
 				fmt.Sprintf("%d", gatewayConfig.DefaultIPv4.Metric),
 				gatewayConfig.DefaultIPv4.Source,
 			}
@@ -74,7 +80,7 @@ func ShowGatewayInformation() error {
 			row := []string{
 				"Default IPv6",
 				gatewayConfig.DefaultIPv6.Interface,
-				gatewayConfig.DefaultIPv6.Gateway,
+				withName(gatewayConfig.DefaultIPv6.Gateway, gatewayConfig.DefaultIPv6.Name),
 				fmt.Sprintf("%d", gatewayConfig.DefaultIPv6.Metric),
 				gatewayConfig.DefaultIPv6.Source,
 			}
@@ -101,7 +107,7 @@ func ShowGatewayInformation() error {
 			row := []string{
 				gateway.IPVersion,
 				gateway.Interface,
-				gateway.Gateway,
+				withName(gateway.Gateway, gateway.Name),
 				fmt.Sprintf("%d", gateway.Metric),
 				gateway.Source,
 			}
@@ -142,6 +148,25 @@ func ShowGatewayInformation() error {
 	return nil
 }
 
+// enrichGatewayNames fills the PTR names of all gateways within the lookup deadline
+func enrichGatewayNames(gatewayConfig *GatewayConfig) {
+	var addrs []string
+	for _, gateway := range gatewayConfig.AllGateways {
+		addrs = append(addrs, gateway.Gateway)
+	}
+	
+	names := LookupPTRs(addrs, utils.PTRLookupDeadline)
+	for i := range gatewayConfig.AllGateways {
+		gatewayConfig.AllGateways[i].Name = names[gatewayConfig.AllGateways[i].Gateway]
+	}
+	if gatewayConfig.DefaultIPv4 != nil {
+		gatewayConfig.DefaultIPv4.Name = names[gatewayConfig.DefaultIPv4.Gateway]
+	}
+	if gatewayConfig.DefaultIPv6 != nil {
+		gatewayConfig.DefaultIPv6.Name = names[gatewayConfig.DefaultIPv6.Gateway]
+	}
+}
+
 // getWindowsGateway retrieves gateway information on Windows using PowerShell
 func getWindowsGateway() (*GatewayConfig, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
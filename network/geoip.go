@@ -0,0 +1,188 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"netinfo/utils"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Well-known locations of the GeoLite2 databases installed by geoipupdate and distro packages
+var (
+	geoIPCityPaths = []string{
+		"/usr/share/GeoIP/GeoLite2-City.mmdb",
+		"/var/lib/GeoIP/GeoLite2-City.mmdb",
+		"/usr/local/share/GeoIP/GeoLite2-City.mmdb",
+	}
+	geoIPASNPaths = []string{
+		"/usr/share/GeoIP/GeoLite2-ASN.mmdb",
+		"/var/lib/GeoIP/GeoLite2-ASN.mmdb",
+		"/usr/local/share/GeoIP/GeoLite2-ASN.mmdb",
+	}
+)
+
+// GeoIPInfo holds the offline location and network owner of an address
+type GeoIPInfo struct {
+	CountryCode  string `json:"country_code,omitempty"`
+	Country      string `json:"country,omitempty"`
+	Region       string `json:"region,omitempty"`
+	City         string `json:"city,omitempty"`
+	TimeZone     string `json:"time_zone,omitempty"`
+	ASN          uint   `json:"asn,omitempty"`
+	Organization string `json:"organization,omitempty"`
+}
+
+// geoIPCityRecord is the subset of a GeoIP2/GeoLite2 City or Country record we use
+type geoIPCityRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	Location struct {
+		TimeZone string `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+}
+
+// geoIPASNRecord is a GeoLite2 ASN record
+type geoIPASNRecord struct {
+	Number       uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+// geoIPDatabases holds the opened readers; either may be nil
+type geoIPDatabases struct {
+	city *maxminddb.Reader
+	asn  *maxminddb.Reader
+}
+
+var (
+	geoIPDB      geoIPDatabases
+	geoIPOnce    sync.Once
+	geoIPEnabled bool
+)
+
+// SetGeoIPConnections enables or disables GeoIP enrichment of connection remotes
+func SetGeoIPConnections(enabled bool) {
+	geoIPEnabled = enabled
+}
+
+// GeoIPConnectionsEnabled reports whether the connections view should show locations
+func GeoIPConnectionsEnabled() bool {
+	return (geoIPEnabled || utils.GetConfig().GeoIPConnections) && GeoIPAvailable()
+}
+
+// openGeoIP opens the configured databases, or the first one found in the usual locations
+func openGeoIP() {
+	geoIPOnce.Do(func() {
+		cfg := utils.GetConfig()
+		geoIPDB.city = openMMDB(cfg.GeoIPCityDB, geoIPCityPaths)
+		geoIPDB.asn = openMMDB(cfg.GeoIPASNDB, geoIPASNPaths)
+	})
+}
+
+// openMMDB opens path, or the first existing fallback when path is empty
+func openMMDB(path string, fallbacks []string) *maxminddb.Reader {
+	candidates := fallbacks
+	if path != "" {
+		candidates = []string{path}
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err != nil {
+			continue
+		}
+		reader, err := maxminddb.Open(candidate)
+		if err == nil {
+			return reader
+		}
+	}
+	return nil
+}
+
+// GeoIPAvailable reports whether at least one GeoIP database could be opened
+func GeoIPAvailable() bool {
+	openGeoIP()
+	return geoIPDB.city != nil || geoIPDB.asn != nil
+}
+
+// LookupGeoIP returns the location and ASN of an address from the local databases
+func LookupGeoIP(addr string) (*GeoIPInfo, error) {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil, utils.WrapError(nil, fmt.Sprintf("invalid IP address: %s", addr), utils.ErrorTypeValidation)
+	}
+	if !GeoIPAvailable() {
+		return nil, utils.WrapError(nil, "no GeoIP database configured", utils.ErrorTypeValidation)
+	}
+
+	info := &GeoIPInfo{}
+	if geoIPDB.city != nil {
+		var record geoIPCityRecord
+		if err := geoIPDB.city.Lookup(ip, &record); err != nil {
+			return nil, utils.WrapError(err, "GeoIP city lookup failed", utils.ErrorTypeParse)
+		}
+		info.CountryCode = record.Country.ISOCode
+		info.Country = record.Country.Names["en"]
+		info.City = record.City.Names["en"]
+		info.TimeZone = record.Location.TimeZone
+		if len(record.Subdivisions) > 0 {
+			info.Region = record.Subdivisions[0].Names["en"]
+		}
+	}
+	if geoIPDB.asn != nil {
+		var record geoIPASNRecord
+		if err := geoIPDB.asn.Lookup(ip, &record); err != nil {
+			return nil, utils.WrapError(err, "GeoIP ASN lookup failed", utils.ErrorTypeParse)
+		}
+		info.ASN = record.Number
+		info.Organization = record.Organization
+	}
+
+	return info, nil
+}
+
+// Summary returns a short "CC, City AS123 Org" description
+func (g *GeoIPInfo) Summary() string {
+	if g == nil {
+		return ""
+	}
+	var location []string
+	for _, part := range []string{g.CountryCode, g.City} {
+		if part != "" {
+			location = append(location, part)
+		}
+	}
+	summary := strings.Join(location, ", ")
+	if g.ASN != 0 {
+		summary = strings.TrimSpace(fmt.Sprintf("%s AS%d %s", summary, g.ASN, g.Organization))
+	}
+	return summary
+}
+
+// enrichConnectionGeoIP fills Geo for public remote addresses
+func enrichConnectionGeoIP(connections []ConnectionInfo) {
+	cache := make(map[string]*GeoIPInfo)
+	for i := range connections {
+		if connections[i].RemoteAddr == "" {
+			continue
+		}
+		host := hostFromAddr(connections[i].RemoteAddr)
+		if !isPublicAddress(host) {
+			continue
+		}
+		if _, ok := cache[host]; !ok {
+			cache[host], _ = LookupGeoIP(host)
+		}
+		connections[i].Geo = cache[host]
+	}
+}
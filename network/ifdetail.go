@@ -0,0 +1,462 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+const procNetARP = "/proc/net/arp"
+
+// How a DNS server is tied to an interface
+const (
+	DNSBindingLink  = "link"  // configured on the interface (Windows, systemd-resolved)
+	DNSBindingRoute = "route" // a system-wide server the kernel reaches through the interface
+)
+
+// NeighborInfo is one ARP (IPv4) or NDP (IPv6) cache entry
+type NeighborInfo struct {
+	Address string `json:"address"`
+	LLAddr  string `json:"lladdr,omitempty"`
+	State   string `json:"state"` // REACHABLE, STALE, FAILED...
+	Router  bool   `json:"router,omitempty"`
+}
+
+// InterfaceDNSServer is a DNS server used through an interface
+type InterfaceDNSServer struct {
+	Server  string `json:"server"`
+	Binding string `json:"binding"` // DNSBindingLink or DNSBindingRoute
+}
+
+// InterfaceReport gathers everything known about one interface
+type InterfaceReport struct {
+	Interface   *InterfaceInfo       `json:"interface"`
+	Routes      []RouteInfo          `json:"routes"`
+	Gateways    []GatewayInfo        `json:"gateways"`
+	DNSServers  []InterfaceDNSServer `json:"dns_servers"`
+	Neighbors   []NeighborInfo       `json:"neighbors"`
+	Connections []ConnectionInfo     `json:"connections"` // local address on this interface
+}
+
+// GetInterfaceReport collects the addresses, counters, routes, gateways, DNS servers,
+// neighbours and connections of the interface name
+func GetInterfaceReport(name string) (*InterfaceReport, error) {
+	iface, err := GetInterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	single := []InterfaceInfo{*iface}
+	AttachVethPeers(single)
+	iface = &single[0]
+
+	report := &InterfaceReport{
+		Interface:   iface,
+		Routes:      []RouteInfo{},
+		Gateways:    []GatewayInfo{},
+		DNSServers:  []InterfaceDNSServer{},
+		Neighbors:   []NeighborInfo{},
+		Connections: []ConnectionInfo{},
+	}
+
+	var routeConfig *RouteConfig
+	if utils.IsWindows() {
+		routeConfig, err = getWindowsRoutes()
+	} else {
+		routeConfig, err = getLinuxRoutes()
+	}
+	if err == nil {
+		for _, route := range routeConfig.Routes {
+			if route.Interface == name {
+				report.Routes = append(report.Routes, route)
+			}
+		}
+	}
+
+	var gatewayConfig *GatewayConfig
+	if utils.IsWindows() {
+		gatewayConfig, err = getWindowsGateway()
+	} else {
+		gatewayConfig, err = getLinuxGateway()
+	}
+	if err == nil {
+		for _, gateway := range gatewayConfig.AllGateways {
+			if gateway.Interface == name {
+				report.Gateways = append(report.Gateways, gateway)
+			}
+		}
+	}
+
+	report.DNSServers = interfaceDNSServers(name)
+	report.Neighbors = readNeighbors(name)
+
+	if connectionConfig, err := getActiveConnections(); err == nil {
+		local := make(map[string]bool)
+		for _, addr := range iface.Addrs {
+			if ip, _, err := net.ParseCIDR(addr); err == nil {
+				local[ip.String()] = true
+			}
+		}
+		for _, conn := range connectionConfig.Connections {
+			// Link-local addresses carry the zone, e.g. fe80::1%eth0
+			host, _, _ := strings.Cut(hostFromAddr(conn.LocalAddr), "%")
+			if ip := net.ParseIP(host); ip != nil && local[ip.String()] {
+				report.Connections = append(report.Connections, conn)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// interfaceDNSServers returns the servers configured on the interface and the
+// system-wide servers whose route leaves through it
+func interfaceDNSServers(name string) []InterfaceDNSServer {
+	servers := []InterfaceDNSServer{}
+	seen := make(map[string]bool)
+	add := func(server, binding string) {
+		if !seen[server] {
+			seen[server] = true
+			servers = append(servers, InterfaceDNSServer{Server: server, Binding: binding})
+		}
+	}
+
+	for _, server := range resolvedLinkDNS(name) {
+		add(server, DNSBindingLink)
+	}
+
+	dnsConfig, err := getDNSConfig()
+	if err != nil {
+		return servers
+	}
+	for _, dnsInfo := range dnsConfig.Servers {
+		if dnsInfo.Interface == name {
+			for _, server := range dnsInfo.All {
+				add(server, DNSBindingLink)
+			}
+		}
+	}
+	for _, server := range uniqueDNSServers(dnsConfig) {
+		addr, err := netip.ParseAddr(server)
+		if err != nil || seen[server] {
+			continue
+		}
+		// A resolver on this host (e.g. 127.0.0.53) never leaves through a physical interface
+		if dev, _ := kernelSourceAddress(addr); dev == name {
+			add(server, DNSBindingRoute)
+		}
+	}
+	return servers
+}
+
+// resolvedLinkDNS asks systemd-resolved for the per-link servers: "Link 2 (eth0): 192.0.2.1 2001:db8::1"
+func resolvedLinkDNS(name string) []string {
+	if !utils.IsLinux() {
+		return nil
+	}
+	if _, err := exec.LookPath("resolvectl"); err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.LinuxCommandTimeout)
+	defer cancel()
+
+	output, err := utils.CommandWithTimeout(ctx, 5*time.Second, "resolvectl", "dns", name)
+	if err != nil {
+		return nil
+	}
+	_, servers, ok := strings.Cut(strings.TrimSpace(string(output)), "):")
+	if !ok {
+		return nil
+	}
+	return strings.Fields(servers)
+}
+
+// readNeighbors lists the neighbour cache of the interface from "ip -json neigh",
+// falling back to /proc/net/arp (IPv4 only) without iproute2
+func readNeighbors(name string) []NeighborInfo {
+	neighbors := []NeighborInfo{}
+	if !utils.IsLinux() {
+		return neighbors
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.LinuxCommandTimeout)
+	defer cancel()
+
+	output, err := utils.CommandWithTimeout(ctx, 5*time.Second, "ip", "-json", "neigh", "show", "dev", name)
+	if err == nil {
+		var entries []struct {
+			Dst    string          `json:"dst"`
+			LLAddr string          `json:"lladdr"`
+			State  []string        `json:"state"`
+			Router json.RawMessage `json:"router"` // present, as null, on routers
+		}
+		if json.Unmarshal(output, &entries) == nil {
+			for _, entry := range entries {
+				neighbors = append(neighbors, NeighborInfo{
+					Address: entry.Dst,
+					LLAddr:  entry.LLAddr,
+					State:   strings.Join(entry.State, ","),
+					Router:  entry.Router != nil,
+				})
+			}
+			sortNeighbors(neighbors)
+			return neighbors
+		}
+	}
+
+	file, err := os.Open(procNetARP)
+	if err != nil {
+		return neighbors
+	}
+	defer file.Close()
+
+	// "192.0.2.1  0x1  0x2  02:fc:00:00:00:05  *  eth0"; flag 0x2 is ATF_COM (resolved)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 || fields[5] != name || net.ParseIP(fields[0]) == nil {
+			continue
+		}
+		state := "INCOMPLETE"
+		if fields[2] != "0x0" {
+			state = "REACHABLE"
+		}
+		neighbors = append(neighbors, NeighborInfo{Address: fields[0], LLAddr: fields[3], State: state})
+	}
+	sortNeighbors(neighbors)
+	return neighbors
+}
+
+// sortNeighbors puts IPv4 before IPv6, then orders by address
+func sortNeighbors(neighbors []NeighborInfo) {
+	sort.Slice(neighbors, func(i, j int) bool {
+		a, errA := netip.ParseAddr(neighbors[i].Address)
+		b, errB := netip.ParseAddr(neighbors[j].Address)
+		if errA != nil || errB != nil {
+			return neighbors[i].Address < neighbors[j].Address
+		}
+		if a.Is4() != b.Is4() {
+			return a.Is4()
+		}
+		return a.Less(b)
+	})
+}
+
+// InterfaceNames returns the names of all interfaces for pickers
+func InterfaceNames() ([]string, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, utils.WrapError(err, utils.ErrNetworkInterfaces, utils.ErrorTypeNetwork)
+	}
+	var names []string
+	for _, iface := range interfaces {
+		names = append(names, iface.Name)
+	}
+	return names, nil
+}
+
+// ShowInterfaceDetails prints everything about one interface on a single screen
+func ShowInterfaceDetails(name string) error {
+	display.PrintInfo(fmt.Sprintf("Gathering everything about %s...", name))
+
+	// The callers report a missing interface
+	report, err := GetInterfaceReport(name)
+	if err != nil {
+		return err
+	}
+	iface := report.Interface
+
+	status := display.Error(iface.Status)
+	if iface.Status == "UP" {
+		status = display.Success(iface.Status)
+	}
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Interface " + iface.Name
+	tableConfig.Headers = []string{"Interface", "Index", "MAC Address", "MTU", "Status"}
+	tableConfig.Data = [][]string{{
+		interfaceLabel(*iface),
+		fmt.Sprintf("%d", iface.Index),
+		valueOrDash(iface.HardwareAddr),
+		fmt.Sprintf("%d", iface.MTU),
+		status,
+	}}
+	tableConfig.MaxWidth = 50
+	display.PrintTable(tableConfig)
+
+	interfaces := []InterfaceInfo{*iface}
+	printLinkAttributes(interfaces)
+	if iface.Wireless != nil {
+		printWirelessInfo([]*WirelessInfo{iface.Wireless})
+	}
+	if len(iface.AddressDetails) > 0 {
+		printAddressDetails(iface.AddressDetails)
+	} else {
+		display.PrintWarning("No addresses on this interface")
+	}
+
+	TrackCounterGrowth(interfaces, utils.CounterSampleInterval)
+	printInterfaceCounters(interfaces)
+
+	printInterfaceRoutes(report)
+	printInterfaceDNS(report)
+	printNeighbors(report)
+	printInterfaceConnections(report)
+
+	return nil
+}
+
+// printInterfaceRoutes draws the routes and gateways that use the interface
+func printInterfaceRoutes(report *InterfaceReport) {
+	if len(report.Routes) == 0 {
+		display.PrintInfo("No routes use this interface")
+	} else {
+		var tableData [][]string
+		for _, route := range report.Routes {
+			tableData = append(tableData, []string{
+				route.Destination,
+				valueOrDash(route.Gateway),
+				fmt.Sprintf("%d", route.Metric),
+				valueOrDash(route.Protocol),
+				route.Type,
+			})
+		}
+		tableConfig := display.NewTableConfig()
+		tableConfig.Title = "Routes"
+		tableConfig.Headers = []string{"Destination", "Gateway", "Metric", "Protocol", "Type"}
+		tableConfig.Data = tableData
+		tableConfig.MaxWidth = 50
+		display.PrintTable(tableConfig)
+	}
+
+	if len(report.Gateways) == 0 {
+		return
+	}
+	var tableData [][]string
+	for _, gateway := range report.Gateways {
+		tableData = append(tableData, []string{
+			gateway.IPVersion,
+			withName(gateway.Gateway, gateway.Name),
+			fmt.Sprintf("%d", gateway.Metric),
+		})
+	}
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Gateways"
+	tableConfig.Headers = []string{"Version", "Gateway", "Metric"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+	display.PrintTable(tableConfig)
+}
+
+// printInterfaceDNS draws the DNS servers used through the interface
+func printInterfaceDNS(report *InterfaceReport) {
+	if len(report.DNSServers) == 0 {
+		display.PrintInfo("No DNS servers are configured on or reached through this interface")
+		return
+	}
+	var tableData [][]string
+	for _, server := range report.DNSServers {
+		binding := "configured on this interface"
+		if server.Binding == DNSBindingRoute {
+			binding = "system server routed through this interface"
+		}
+		tableData = append(tableData, []string{server.Server, binding})
+	}
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "DNS Servers"
+	tableConfig.Headers = []string{"Server", "Binding"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+	display.PrintTable(tableConfig)
+}
+
+// printNeighbors draws the ARP/NDP entries of the interface
+func printNeighbors(report *InterfaceReport) {
+	if len(report.Neighbors) == 0 {
+		display.PrintInfo("No neighbour entries")
+		return
+	}
+	var tableData [][]string
+	for _, neighbor := range report.Neighbors {
+		state := neighbor.State
+		switch {
+		case strings.Contains(state, "REACHABLE") || strings.Contains(state, "PERMANENT"):
+			state = display.Success(state)
+		case strings.Contains(state, "FAILED") || strings.Contains(state, "INCOMPLETE"):
+			state = display.Error(state)
+		default:
+			state = display.Warning(state)
+		}
+		router := ""
+		if neighbor.Router {
+			router = "router"
+		}
+		tableData = append(tableData, []string{
+			neighbor.Address,
+			valueOrDash(neighbor.LLAddr),
+			state,
+			valueOrDash(router),
+		})
+	}
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Neighbours"
+	tableConfig.Headers = []string{"Address", "MAC Address", "State", "Role"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 50
+	display.PrintTable(tableConfig)
+}
+
+// printInterfaceConnections draws the connections bound to one of the interface's addresses
+func printInterfaceConnections(report *InterfaceReport) {
+	if len(report.Connections) == 0 {
+		display.PrintInfo("No connections use an address of this interface")
+		return
+	}
+
+	connections := report.Connections
+	sort.Slice(connections, func(i, j int) bool {
+		if connections[i].Status != connections[j].Status {
+			return connections[i].Status < connections[j].Status
+		}
+		return connections[i].LocalAddr < connections[j].LocalAddr
+	})
+
+	var tableData [][]string
+	for _, conn := range connections {
+		status := conn.Status
+		switch status {
+		case "ESTABLISHED":
+			status = display.Success(status)
+		case "LISTEN":
+			status = display.Info(status)
+		default:
+			status = display.Warning(status)
+		}
+		tableData = append(tableData, []string{
+			strings.ToUpper(conn.Type),
+			utils.TruncateString(conn.LocalAddr, 45),
+			utils.TruncateString(valueOrDash(conn.RemoteAddr), 45),
+			status,
+			fmt.Sprintf("%d", conn.PID),
+			utils.TruncateString(valueOrDash(conn.Process), 20),
+		})
+	}
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = fmt.Sprintf("Connections (%d)", len(connections))
+	tableConfig.Headers = []string{"Type", "Local Address", "Remote Address", "Status", "PID", "Process"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 100
+	display.PrintTable(tableConfig)
+
+	display.PrintInfo("Sockets bound to all addresses (0.0.0.0, ::) are not listed here")
+}
@@ -21,6 +21,13 @@ type InterfaceInfo struct {
 	Flags        string   `json:"flags"`
 	Addrs        []string `json:"addrs"`
 	Status       string   `json:"status"`
+	
+	Classifications []IPClassification `json:"classifications"`
+	Counters        *InterfaceCounters `json:"counters,omitempty"`
+	Link            *LinkAttributes    `json:"link,omitempty"`
+	Container       *VethPeer          `json:"container,omitempty"` // where the other end of a veth lives
+	AddressDetails  []AddressDetail    `json:"address_details,omitempty"`
+	Wireless        *WirelessInfo      `json:"wireless,omitempty"`
 }
 
 // GetNetworkInterfaces retrieves all network interfaces information
@@ -57,9 +64,14 @@ func GetNetworkInterfaces() ([]InterfaceInfo, error) {
 		for _, addr := range psInterface.Addrs {
 			interfaceInfo.Addrs = append(interfaceInfo.Addrs, addr.Addr)
 		}
+		interfaceInfo.Classifications = classifyAddresses(interfaceInfo.Addrs)
 		
 		interfaces = append(interfaces, interfaceInfo)
 	}
+	attachCounters(interfaces)
+	attachLinkAttributes(interfaces)
+	attachAddressDetails(interfaces)
+	attachWireless(interfaces)
 	
 	return interfaces, nil
 }
@@ -118,9 +130,14 @@ func GetNetworkInterfacesDetailed() ([]InterfaceInfo, error) {
 		for _, addr := range addrs {
 			interfaceInfo.Addrs = append(interfaceInfo.Addrs, addr.String())
 		}
+		interfaceInfo.Classifications = classifyAddresses(interfaceInfo.Addrs)
 		
 		interfaces = append(interfaces, interfaceInfo)
 	}
+	attachCounters(interfaces)
+	attachLinkAttributes(interfaces)
+	attachAddressDetails(interfaces)
+	attachWireless(interfaces)
 	
 	return interfaces, nil
 }
@@ -180,6 +197,7 @@ func ShowNetworkInterfaces() error {
 		display.PrintWarning(utils.MsgNoInterfaces)
 		return nil
 	}
+	AttachVethPeers(interfaces)
 	
 	// Create table data
 	var tableData [][]string
@@ -210,7 +228,7 @@ func ShowNetworkInterfaces() error {
 		}
 		
 		row := []string{
-			iface.Name,
+			interfaceLabel(iface),
 			addresses,
 			macAddr,
 			fmt.Sprintf("%d", iface.MTU),
@@ -229,6 +247,35 @@ func ShowNetworkInterfaces() error {
 	
 	display.PrintTable(tableConfig)
 	
+	printLinkAttributes(interfaces)
+	
+	var wireless []*WirelessInfo
+	for _, iface := range interfaces {
+		if iface.Wireless != nil {
+			wireless = append(wireless, iface.Wireless)
+		}
+	}
+	printWirelessInfo(wireless)
+	
+	// Show what kind of address each one is
+	var classes []addressClassRow
+	for _, iface := range interfaces {
+		for _, classification := range iface.Classifications {
+			classes = append(classes, addressClassRow{iface.Name, classification})
+		}
+	}
+	printAddressClasses(classes)
+	
+	var details []AddressDetail
+	for _, iface := range interfaces {
+		details = append(details, iface.AddressDetails...)
+	}
+	printAddressDetails(details)
+	
+	// Second counter sample to tell old errors from ones still happening
+	TrackCounterGrowth(interfaces, utils.CounterSampleInterval)
+	printInterfaceCounters(interfaces)
+	
 	// Show summary
 	activeCount := 0
 	for _, iface := range interfaces {
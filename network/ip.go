@@ -16,11 +16,26 @@ import (
 
 // IPInfo holds IP address information
 type IPInfo struct {
-	LocalIPs  []string `json:"local_ips"`
-	PublicIP  string   `json:"public_ip"`
-	IPv4      string   `json:"ipv4"`
-	IPv6      string   `json:"ipv6"`
-	Interface string   `json:"interface"`
+	LocalIPs       []string `json:"local_ips"`
+	PublicIP       string   `json:"public_ip"`
+	PublicIPMethod string   `json:"public_ip_method"` // http, dns or stun
+	IPv4           string   `json:"ipv4"`
+	IPv6           string   `json:"ipv6"`
+	Interface      string   `json:"interface"`
+	
+	// Every address with its prefix length, e.g. 192.168.1.10/24
+	Addresses []string `json:"addresses"`
+	IPv4Addrs []string `json:"ipv4_addrs"`
+	IPv6Addrs []string `json:"ipv6_addrs"`
+	
+	Classifications []IPClassification `json:"classifications"`
+	AddressDetails  []AddressDetail    `json:"address_details,omitempty"`
+}
+
+// IPReport holds local and public addresses for JSON output
+type IPReport struct {
+	Interfaces []IPInfo        `json:"interfaces"`
+	Public     *PublicIPReport `json:"public"`
 }
 
 // PublicIPResponse represents response from public IP services
@@ -28,7 +43,7 @@ type PublicIPResponse struct {
 	IP string `json:"ip"`
 }
 
-// Public IP service endpoints with fallback
+// Built-in public IP service endpoints, overridden by public_ip_endpoints in the config
 var PublicIPEndpoints = []string{
 	"https://api.ipify.org",
 	"https://ifconfig.me/ip",
@@ -47,6 +62,9 @@ func GetLocalIPs() ([]IPInfo, error) {
 		return nil, fmt.Errorf("failed to get interfaces: %v", err)
 	}
 	
+	// Prefix, scope, flags and lifetimes of every address
+	details, _ := GetAddressDetails()
+	
 	for _, iface := range interfaces {
 		// Skip down interfaces
 		if iface.Flags&net.FlagUp == 0 {
@@ -63,8 +81,10 @@ func GetLocalIPs() ([]IPInfo, error) {
 			continue
 		}
 		
-		var localIPs []string
+		var localIPs, prefixes, ipv4Addrs, ipv6Addrs []string
 		var ipv4, ipv6 string
+		var ipv4Usable, ipv6Usable bool
+		var addrDetails []AddressDetail
 		
 		for _, addr := range addrs {
 			ipNet, ok := addr.(*net.IPNet)
@@ -79,21 +99,40 @@ func GetLocalIPs() ([]IPInfo, error) {
 			
 			ipStr := ip.String()
 			localIPs = append(localIPs, ipStr)
+			prefixes = append(prefixes, ipNet.String())
 			
-			// Separate IPv4 and IPv6
+			// Separate IPv4 and IPv6, keeping the first usable address of each as the primary one
+			usable := true
+			for _, detail := range details[iface.Name] {
+				if detail.Address == ipStr {
+					usable = detail.Usable() && !detail.HasFlag(AddrFlagSecondary)
+					addrDetails = append(addrDetails, detail)
+				}
+			}
 			if ip.To4() != nil {
-				ipv4 = ipStr
+				if ipv4 == "" || (usable && !ipv4Usable) {
+					ipv4, ipv4Usable = ipStr, usable
+				}
+				ipv4Addrs = append(ipv4Addrs, ipNet.String())
 			} else {
-				ipv6 = ipStr
+				if ipv6 == "" || (usable && !ipv6Usable) {
+					ipv6, ipv6Usable = ipStr, usable
+				}
+				ipv6Addrs = append(ipv6Addrs, ipNet.String())
 			}
 		}
 		
 		if len(localIPs) > 0 {
 			ipInfo := IPInfo{
-				LocalIPs:  localIPs,
-				IPv4:      ipv4,
-				IPv6:      ipv6,
-				Interface: iface.Name,
+				LocalIPs:        localIPs,
+				IPv4:            ipv4,
+				IPv6:            ipv6,
+				Interface:       iface.Name,
+				Addresses:       prefixes,
+				IPv4Addrs:       ipv4Addrs,
+				IPv6Addrs:       ipv6Addrs,
+				Classifications: classifyAddresses(localIPs),
+				AddressDetails:  addrDetails,
 			}
 			ipInfos = append(ipInfos, ipInfo)
 		}
@@ -102,100 +141,62 @@ func GetLocalIPs() ([]IPInfo, error) {
 	return ipInfos, nil
 }
 
-// GetPublicIP retrieves public IP address with fallback endpoints
+// GetIPReport collects local addresses and the public addresses of both families
+func GetIPReport() (*IPReport, error) {
+	localIPs, err := GetLocalIPs()
+	if err != nil {
+		return nil, err
+	}
+	
+	report := DiscoverPublicIPs()
+	attachPublicIPs(localIPs, report)
+	
+	return &IPReport{Interfaces: localIPs, Public: report}, nil
+}
+
+// GetPublicIP retrieves the consensus public IP address, preferring IPv4
 func GetPublicIP() (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), utils.PublicIPTimout)
-	defer cancel()
+	report := DiscoverPublicIPs()
 	
-	client := &http.Client{
-		Timeout: utils.HTTPTimeout,
+	for _, result := range []*PublicIPResult{report.IPv4, report.IPv6} {
+		if result != nil && result.IP != "" {
+			return result.IP, nil
+		}
 	}
 	
-	var lastErr error
-	for _, endpoint := range PublicIPEndpoints {
-		select {
-		case <-ctx.Done():
-			return "", utils.WrapError(nil, utils.ErrPublicIP, utils.ErrorTypeTimeout)
-		default:
-		}
-		
-		req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
-		if err != nil {
-			lastErr = utils.WrapError(err, "Failed to create request", utils.ErrorTypeNetwork)
-			continue
-		}
-		
-		resp, err := client.Do(req)
-		if err != nil {
-			lastErr = utils.WrapError(err, "Request failed", utils.ErrorTypeNetwork)
-			continue
-		}
-		
-		if resp.StatusCode != http.StatusOK {
-			resp.Body.Close()
-			lastErr = utils.WrapError(nil, fmt.Sprintf("HTTP %d", resp.StatusCode), utils.ErrorTypeNetwork)
+	// If we get here, all endpoints failed
+	return "", utils.WrapError(nil, utils.ErrPublicIP, utils.ErrorTypeNetwork)
+}
+
+// attachPublicIPs records the public address and discovery method on the interfaces holding the default routes
+func attachPublicIPs(localIPs []IPInfo, report *PublicIPReport) {
+	for _, result := range []*PublicIPResult{report.IPv6, report.IPv4} {
+		if result.IP == "" {
 			continue
 		}
-		
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		
+		gateway, err := GetDefaultGateway(result.Family)
 		if err != nil {
-			lastErr = utils.WrapError(err, "Failed to read response", utils.ErrorTypeParse)
 			continue
 		}
-		
-		// Clean the response
-		publicIP := strings.TrimSpace(string(body))
-		
-		// Validate IP address
-		if net.ParseIP(publicIP) != nil {
-			return publicIP, nil
+		for i := range localIPs {
+			if localIPs[i].Interface == gateway.Interface {
+				localIPs[i].PublicIP = result.IP
+				localIPs[i].PublicIPMethod = result.Method
+			}
 		}
-		
-		lastErr = utils.WrapError(nil, "Invalid IP address received", utils.ErrorTypeParse)
 	}
-	
-	// If we get here, all endpoints failed
-	if lastErr != nil {
-		return "", utils.WrapError(lastErr, utils.ErrPublicIP, utils.ErrorTypeNetwork)
-	}
-	
-	return "", utils.WrapError(nil, utils.ErrPublicIP, utils.ErrorTypeNetwork)
 }
 
 // GetPublicIPWithService tries to get public IP from a specific service
 func GetPublicIPWithService(endpoint string) (string, error) {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-	
-	resp, err := client.Get(endpoint)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
-	}
-	
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-	
-	publicIP := strings.TrimSpace(string(body))
-	
-	// Validate IP address
-	if net.ParseIP(publicIP) == nil {
-		return "", fmt.Errorf("invalid IP address: %s", publicIP)
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), utils.HTTPTimeout)
+	defer cancel()
 	
-	return publicIP, nil
+	return fetchPublicIP(ctx, &http.Client{Timeout: utils.HTTPTimeout}, endpoint)
 }
 
-// GetIPLocation gets approximate location for an IP (using ipapi.co)
+// GetIPLocation gets approximate location for an IP (using ipapi.co).
+// Prefer LookupGeoIP, which answers from local databases without network calls.
 func GetIPLocation(ip string) (map[string]interface{}, error) {
 	client := &http.Client{
 		Timeout: 5 * time.Second,
@@ -238,6 +239,11 @@ func ShowIPInformation() error {
 		return err
 	}
 	
+	// Get public IPs for both address families
+	display.PrintInfo("Getting public IP addresses...")
+	report := DiscoverPublicIPs()
+	attachPublicIPs(localIPs, report)
+	
 	// Display local IPs
 	if len(localIPs) == 0 {
 		display.PrintWarning(utils.MsgNoIPs)
@@ -247,56 +253,142 @@ func ShowIPInformation() error {
 		// Create table for local IPs
 		var tableData [][]string
 		for _, ipInfo := range localIPs {
-			// Join all local IPs
-			allIPs := strings.Join(ipInfo.LocalIPs, ", ")
+			// Join all local addresses with their prefixes
+			allIPs := strings.Join(ipInfo.Addresses, ", ")
 			if len(allIPs) > 40 {
 				allIPs = utils.TruncateString(allIPs, 40)
 			}
 			
+			publicIP := "-"
+			if ipInfo.PublicIP != "" {
+				publicIP = fmt.Sprintf("%s (%s)", ipInfo.PublicIP, ipInfo.PublicIPMethod)
+			}
+			
 			row := []string{
 				ipInfo.Interface,
 				ipInfo.IPv4,
 				ipInfo.IPv6,
 				allIPs,
+				publicIP,
 			}
 			tableData = append(tableData, row)
 		}
 		
 		tableConfig := display.NewTableConfig()
 		tableConfig.Title = "Local IP Addresses"
-		tableConfig.Headers = []string{"Interface", "IPv4", "IPv6", "All IPs"}
+		tableConfig.Headers = []string{"Interface", "IPv4", "IPv6", "All IPs", "Public IP"}
 		tableConfig.Data = tableData
 		tableConfig.MaxWidth = 60
 		
 		display.PrintTable(tableConfig)
+		
+		var classes []addressClassRow
+		for _, ipInfo := range localIPs {
+			for _, classification := range ipInfo.Classifications {
+				classes = append(classes, addressClassRow{ipInfo.Interface, classification})
+			}
+		}
+		printAddressClasses(classes)
+		
+		var details []AddressDetail
+		for _, ipInfo := range localIPs {
+			details = append(details, ipInfo.AddressDetails...)
+		}
+		printAddressDetails(details)
 	}
 	
-	// Get public IP
-	display.PrintInfo("Getting public IP address...")
-	publicIP, err := GetPublicIP()
-	if err != nil {
-		userMsg := utils.GetUserFriendlyMessage(err)
-		display.PrintError(userMsg)
+	// Display public IPs
+	publicIP := ""
+	
+	var tableData [][]string
+	for _, result := range []*PublicIPResult{report.IPv4, report.IPv6} {
+		if result.IP == "" {
+			tableData = append(tableData, []string{result.Family, display.Error("Not available"), "-", "-", "-"})
+			continue
+		}
+		if publicIP == "" {
+			publicIP = result.IP
+		}
+		
+		agreement := display.Success(fmt.Sprintf("%d/%d agree", result.Votes, result.Responses))
+		if !result.Agree {
+			agreement = display.Warning(fmt.Sprintf("%d/%d agree", result.Votes, result.Responses))
+		}
+		
+		row := []string{
+			result.Family,
+			display.IP(result.IP),
+			result.Method,
+			result.Endpoint,
+			agreement,
+		}
+		tableData = append(tableData, row)
+	}
+	
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Public IP Addresses"
+	tableConfig.Headers = []string{"Family", "Public IP", "Method", "Answered By", "Consensus"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+	
+	display.PrintTable(tableConfig)
+	
+	// Flag endpoints that disagree, e.g. behind load-balanced NAT
+	for _, result := range []*PublicIPResult{report.IPv4, report.IPv6} {
+		if result.Agree || result.Responses == 0 {
+			continue
+		}
+		display.PrintWarning(fmt.Sprintf("%s endpoints disagree (load-balanced NAT or multiple egress paths?):", result.Family))
+		for _, observation := range result.Observations {
+			if observation.IP != "" && observation.IP != result.IP {
+				display.PrintWarning(fmt.Sprintf("  • %s returned %s", observation.Endpoint, observation.IP))
+			}
+		}
+	}
+	
+	if publicIP == "" {
+		display.PrintError(utils.MsgPublicIPFailed)
 		display.PrintWarning(utils.MsgTryAgain)
 	} else {
-		display.PrintSuccess(fmt.Sprintf("Public IP: %s", display.IP(publicIP)))
-		
-		// Try to get location information
-		display.PrintInfo("Getting location information...")
-		location, err := GetIPLocation(publicIP)
-		if err != nil {
-			display.PrintWarning("Could not retrieve location information")
-		} else {
-			// Display location information
+		for _, result := range []*PublicIPResult{report.IPv4, report.IPv6} {
+			if result.Geo == nil {
+				continue
+			}
+			// Offline lookup from the local GeoIP databases
+			asn := "-"
+			if result.Geo.ASN != 0 {
+				asn = fmt.Sprintf("AS%d", result.Geo.ASN)
+			}
 			locationInfo := map[string]string{
-				"Country":    getString(location, "country_name"),
-				"Region":     getString(location, "region"),
-				"City":       getString(location, "city"),
-				"ISP":        getString(location, "org"),
-				"Timezone":   getString(location, "timezone"),
+				"Country":      result.Geo.Country,
+				"Region":       result.Geo.Region,
+				"City":         result.Geo.City,
+				"ASN":          asn,
+				"Organization": result.Geo.Organization,
+				"Timezone":     result.Geo.TimeZone,
 			}
 			
-			display.PrintKeyValue(locationInfo, "Public IP Location")
+			display.PrintKeyValue(locationInfo, fmt.Sprintf("Public %s Location (GeoIP database)", result.Family))
+		}
+		
+		if !GeoIPAvailable() {
+			// No local database; fall back to the online lookup
+			display.PrintInfo("Getting location information...")
+			location, err := GetIPLocation(publicIP)
+			if err != nil {
+				display.PrintWarning("Could not retrieve location information")
+			} else {
+				// Display location information
+				locationInfo := map[string]string{
+					"Country":    getString(location, "country_name"),
+					"Region":     getString(location, "region"),
+					"City":       getString(location, "city"),
+					"ISP":        getString(location, "org"),
+					"Timezone":   getString(location, "timezone"),
+				}
+				
+				display.PrintKeyValue(locationInfo, "Public IP Location")
+			}
 		}
 	}
 	
@@ -304,10 +396,12 @@ func ShowIPInformation() error {
 	display.PrintSeparator()
 	display.PrintInfo("IP Information Summary:")
 	display.PrintInfo(fmt.Sprintf("  • Local interfaces: %d", len(localIPs)))
-	if publicIP != "" {
-		display.PrintInfo(fmt.Sprintf("  • Public IP: %s", publicIP))
-	} else {
-		display.PrintInfo("  • Public IP: Not available")
+	for _, result := range []*PublicIPResult{report.IPv4, report.IPv6} {
+		if result.IP != "" {
+			display.PrintInfo(fmt.Sprintf("  • Public %s: %s", result.Family, result.IP))
+		} else {
+			display.PrintInfo(fmt.Sprintf("  • Public %s: Not available", result.Family))
+		}
 	}
 	
 	return nil
@@ -326,25 +420,12 @@ func getString(m map[string]interface{}, key string) string {
 // GetPrimaryLocalIP returns the primary local IP (usually the first non-loopback IPv4)
 // (removed) GetPrimaryLocalIP: unused in application
 
-// IsPrivateIP checks if an IP address is private
+// IsPrivateIP checks if an IP address is in a private or local-only range
+// (RFC 1918, loopback, link-local, CGNAT or IPv6 unique local)
 func IsPrivateIP(ip string) bool {
-	parsedIP := net.ParseIP(ip)
-	if parsedIP == nil {
-		return false
+	switch ClassifyIP(ip).Class {
+	case IPClassPrivate, IPClassLoopback, IPClassLinkLocal, IPClassCGNAT, IPClassULA:
+		return true
 	}
-	
-	private := []*net.IPNet{
-		{IP: net.IPv4(10, 0, 0, 0), Mask: net.IPv4Mask(255, 0, 0, 0)},
-		{IP: net.IPv4(172, 16, 0, 0), Mask: net.IPv4Mask(255, 240, 0, 0)},
-		{IP: net.IPv4(192, 168, 0, 0), Mask: net.IPv4Mask(255, 255, 0, 0)},
-		{IP: net.IPv4(127, 0, 0, 0), Mask: net.IPv4Mask(255, 0, 0, 0)},
-	}
-	
-	for _, p := range private {
-		if p.Contains(parsedIP) {
-			return true
-		}
-	}
-	
 	return false
-}
\ No newline at end of file
+}
@@ -0,0 +1,276 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+// PublicIPRecord is one public IP observation in the history file
+type PublicIPRecord struct {
+	Time      time.Time `json:"time"`
+	Family    string    `json:"family"`
+	IP        string    `json:"ip"`
+	Method    string    `json:"method"`
+	Endpoint  string    `json:"endpoint"`
+	Votes     int       `json:"votes"`
+	Responses int       `json:"responses"`
+}
+
+// PublicIPChange is a change of the public address of one family
+type PublicIPChange struct {
+	Time   time.Time `json:"time"`
+	Family string    `json:"family"`
+	OldIP  string    `json:"old_ip"`
+	NewIP  string    `json:"new_ip"`
+}
+
+// recordPublicIPs appends the addresses of a report to the history file
+func recordPublicIPs(report *PublicIPReport) error {
+	path := utils.GetConfig().PublicIPHistoryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	now := time.Now()
+	encoder := json.NewEncoder(file)
+	for _, result := range []*PublicIPResult{report.IPv4, report.IPv6} {
+		if result == nil || result.IP == "" {
+			continue
+		}
+		record := PublicIPRecord{
+			Time:      now,
+			Family:    result.Family,
+			IP:        result.IP,
+			Method:    result.Method,
+			Endpoint:  result.Endpoint,
+			Votes:     result.Votes,
+			Responses: result.Responses,
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadPublicIPHistory reads every observation from the history file, oldest first
+func LoadPublicIPHistory() ([]PublicIPRecord, error) {
+	file, err := os.Open(utils.GetConfig().PublicIPHistoryPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []PublicIPRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record PublicIPRecord
+		// Skip lines damaged by an interrupted write
+		if err := json.Unmarshal(scanner.Bytes(), &record); err == nil {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// PublicIPChanges returns the observations where a family's address differed from the previous one
+func PublicIPChanges(records []PublicIPRecord) []PublicIPChange {
+	var changes []PublicIPChange
+	last := make(map[string]string)
+	for _, record := range records {
+		if previous, ok := last[record.Family]; ok && previous != record.IP {
+			changes = append(changes, PublicIPChange{
+				Time:   record.Time,
+				Family: record.Family,
+				OldIP:  previous,
+				NewIP:  record.IP,
+			})
+		}
+		last[record.Family] = record.IP
+	}
+	return changes
+}
+
+// ShowPublicIPHistory displays the recorded public IP changes
+func ShowPublicIPHistory() error {
+	records, err := LoadPublicIPHistory()
+	if err != nil {
+		display.PrintError(fmt.Sprintf("Failed to read history: %v", err))
+		return err
+	}
+	if len(records) == 0 {
+		display.PrintWarning(fmt.Sprintf("No public IP history yet (%s)", utils.GetConfig().PublicIPHistoryPath()))
+		return nil
+	}
+
+	display.PrintSuccess(fmt.Sprintf("%d observations from %s to %s",
+		len(records),
+		records[0].Time.Format(time.RFC3339),
+		records[len(records)-1].Time.Format(time.RFC3339)))
+
+	// Current address of each family, when it was first seen and when last confirmed
+	current := make(map[string]PublicIPRecord)
+	lastSeen := make(map[string]time.Time)
+	for _, record := range records {
+		if previous, ok := current[record.Family]; !ok || previous.IP != record.IP {
+			current[record.Family] = record
+		}
+		lastSeen[record.Family] = record.Time
+	}
+	for _, family := range []string{"IPv4", "IPv6"} {
+		if record, ok := current[family]; ok {
+			display.PrintInfo(fmt.Sprintf("  • %s: %s since %s, last seen %s", family, record.IP,
+				record.Time.Format(time.RFC3339), lastSeen[family].Format(time.RFC3339)))
+		}
+	}
+
+	changes := PublicIPChanges(records)
+	if len(changes) == 0 {
+		display.PrintSuccess("No address changes recorded")
+		return nil
+	}
+
+	var tableData [][]string
+	for _, change := range changes {
+		row := []string{
+			change.Time.Format("2006-01-02 15:04:05"),
+			change.Family,
+			change.OldIP,
+			display.IP(change.NewIP),
+		}
+		tableData = append(tableData, row)
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Public IP Changes"
+	tableConfig.Headers = []string{"Time", "Family", "Old IP", "New IP"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+
+	display.PrintTable(tableConfig)
+
+	return nil
+}
+
+// WatchPublicIP polls the public addresses until interrupted and alerts on every change
+func WatchPublicIP(interval time.Duration, hook, webhook string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Start from the last recorded addresses so a change while not watching is reported too
+	last := make(map[string]string)
+	if records, err := LoadPublicIPHistory(); err == nil {
+		for _, record := range records {
+			last[record.Family] = record.IP
+		}
+	}
+
+	display.PrintInfo(fmt.Sprintf("Watching public IP every %s (Ctrl-C to stop)", interval))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report := DiscoverPublicIPs()
+		now := time.Now()
+		for _, result := range []*PublicIPResult{report.IPv4, report.IPv6} {
+			if result.IP == "" {
+				continue
+			}
+			previous := last[result.Family]
+			last[result.Family] = result.IP
+			if previous == result.IP {
+				continue
+			}
+
+			// The first address of a fresh history is not a change; only show it
+			if previous == "" {
+				display.PrintInfo(fmt.Sprintf("%s %s: %s", now.Format("15:04:05"), result.Family, result.IP))
+				continue
+			}
+			change := PublicIPChange{Time: now, Family: result.Family, OldIP: previous, NewIP: result.IP}
+			display.PrintWarning(fmt.Sprintf("%s %s changed: %s -> %s", now.Format("15:04:05"), change.Family, change.OldIP, change.NewIP))
+			notifyPublicIPChange(change, hook, webhook)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// notifyPublicIPChange runs the hook command and posts to the webhook
+func notifyPublicIPChange(change PublicIPChange, hook, webhook string) {
+	if hook != "" {
+		if err := runPublicIPHook(change, hook); err != nil {
+			display.PrintError(fmt.Sprintf("Hook failed: %v", err))
+		}
+	}
+	if webhook != "" {
+		if err := postJSONWebhook(change, webhook); err != nil {
+			display.PrintError(fmt.Sprintf("Webhook failed: %v", err))
+		}
+	}
+}
+
+// runPublicIPHook runs hook through the shell with the change in its environment
+func runPublicIPHook(change PublicIPChange, hook string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), utils.CommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook)
+	if utils.IsWindows() {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", hook)
+	}
+	cmd.Env = append(os.Environ(),
+		"NETINFO_IP_FAMILY="+change.Family,
+		"NETINFO_OLD_IP="+change.OldIP,
+		"NETINFO_NEW_IP="+change.NewIP,
+		"NETINFO_CHANGED_AT="+change.Time.Format(time.RFC3339),
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// postJSONWebhook sends v as JSON to url
+func postJSONWebhook(v interface{}, url string) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: utils.WebhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+// Interface kinds
+const (
+	LinkKindPhysical  = "physical"
+	LinkKindLoopback  = "loopback"
+	LinkKindBridge    = "bridge"
+	LinkKindBond      = "bond"
+	LinkKindVLAN      = "vlan"
+	LinkKindVeth      = "veth"
+	LinkKindTun       = "tun"
+	LinkKindTap       = "tap"
+	LinkKindWireGuard = "wireguard"
+	LinkKindMacvlan   = "macvlan"
+	LinkKindVXLAN     = "vxlan"
+	LinkKindDummy     = "dummy"
+	LinkKindVirtual   = "virtual" // software device of a kind not listed above
+)
+
+// Linux link constants from if_arp.h, if.h and netdevice.h
+const (
+	arphrdEther      = 1
+	arphrdLoopback   = 772
+	iffUp            = 0x1
+	netAddrPermanent = 0 // addr_assign_type: address burned into the device
+)
+
+// LinkAttributes holds the link-layer details of an interface (Linux only)
+type LinkAttributes struct {
+	Kind         string `json:"kind"`
+	Driver       string `json:"driver,omitempty"`
+	AdminUp      bool   `json:"admin_up"`   // IFF_UP, set with "ip link set up"
+	OperState    string `json:"oper_state"` // RFC 2863 state: up, down, dormant, lowerlayerdown, unknown...
+	Carrier      *bool  `json:"carrier,omitempty"`
+	SpeedMbps    int    `json:"speed_mbps,omitempty"`
+	Duplex       string `json:"duplex,omitempty"`
+	TxQueueLen   int    `json:"tx_queue_len"`
+	Master       string `json:"master,omitempty"` // bridge or bond this device is enslaved to
+	Parent       string `json:"parent,omitempty"` // lower device of a vlan or macvlan
+	PermanentMAC string `json:"permanent_mac,omitempty"`
+}
+
+// ipLink is the part of "ip -details -json link show" used here
+type ipLink struct {
+	IfIndex     int    `json:"ifindex"`
+	IfName      string `json:"ifname"`
+	Link        string `json:"link"`         // parent device, or veth peer in this namespace
+	LinkIndex   int    `json:"link_index"`   // ifindex of the peer when it is in another namespace
+	LinkNetnsID *int   `json:"link_netnsid"` // set when the peer is in another namespace
+	Master      string `json:"master"`
+	PermAddr    string `json:"permaddr"`
+	LinkInfo    struct {
+		InfoKind      string          `json:"info_kind"`
+		InfoData      json.RawMessage `json:"info_data"`
+		InfoSlaveKind string          `json:"info_slave_kind"`
+		InfoSlaveData json.RawMessage `json:"info_slave_data"`
+	} `json:"linkinfo"`
+}
+
+// readIPLinks asks the kernel over netlink (through iproute2) for every link's details, by name
+func readIPLinks() map[string]ipLink {
+	ctx, cancel := context.WithTimeout(context.Background(), utils.LinuxCommandTimeout)
+	defer cancel()
+
+	links := make(map[string]ipLink)
+	output, err := utils.CommandWithTimeout(ctx, 5*time.Second, "ip", "-details", "-json", "link", "show")
+	if err != nil {
+		return links
+	}
+	var list []ipLink
+	if err := json.Unmarshal(output, &list); err != nil {
+		return links
+	}
+	for _, link := range list {
+		links[link.IfName] = link
+	}
+	return links
+}
+
+// readSysfs returns the trimmed content of /sys/class/net/<name>/<attr>, or "" if unreadable
+func readSysfs(name, attr string) string {
+	data, err := os.ReadFile(filepath.Join(sysClassNet, name, attr))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// sysfsInt reads a numeric sysfs attribute; flags style hex values are accepted
+func sysfsInt(name, attr string) (int64, bool) {
+	value := readSysfs(name, attr)
+	if value == "" {
+		return 0, false
+	}
+	number, err := strconv.ParseInt(value, 0, 64)
+	return number, err == nil
+}
+
+// GetLinkAttributes reads the link details of one interface from sysfs and netlink
+func GetLinkAttributes(name string) (*LinkAttributes, error) {
+	if !utils.IsLinux() {
+		return nil, fmt.Errorf("link attributes are only available on Linux")
+	}
+	link, ok := readIPLinks()[name]
+	return linkAttributes(name, link, ok)
+}
+
+// linkAttributes builds the attributes of one interface; link is its netlink entry when found
+func linkAttributes(name string, link ipLink, haveLink bool) (*LinkAttributes, error) {
+	if _, err := os.Stat(filepath.Join(sysClassNet, name)); err != nil {
+		return nil, fmt.Errorf("interface %s not found", name)
+	}
+
+	attrs := &LinkAttributes{
+		Kind:      linkKind(name, link),
+		Driver:    linkDriver(name, link),
+		OperState: readSysfs(name, "operstate"),
+		Duplex:    readSysfs(name, "duplex"),
+		Master:    filepath.Base(readLink(filepath.Join(sysClassNet, name, "master"))),
+	}
+	if attrs.Master == "." {
+		attrs.Master = ""
+	}
+	if attrs.Duplex == "unknown" {
+		attrs.Duplex = ""
+	}
+
+	if flags, ok := sysfsInt(name, "flags"); ok {
+		attrs.AdminUp = flags&iffUp != 0
+	}
+	// Reading carrier fails while the device is administratively down
+	if carrier, ok := sysfsInt(name, "carrier"); ok {
+		up := carrier == 1
+		attrs.Carrier = &up
+	}
+	// Virtual devices report -1 or nothing
+	if speed, ok := sysfsInt(name, "speed"); ok && speed > 0 {
+		attrs.SpeedMbps = int(speed)
+	}
+	if queueLen, ok := sysfsInt(name, "tx_queue_len"); ok {
+		attrs.TxQueueLen = int(queueLen)
+	}
+
+	if haveLink {
+		if attrs.Master == "" {
+			attrs.Master = link.Master
+		}
+		// For a veth the link is its peer, not a lower device
+		if attrs.Kind == LinkKindVLAN || attrs.Kind == LinkKindMacvlan {
+			attrs.Parent = link.Link
+		}
+		attrs.PermanentMAC = link.PermAddr
+	}
+	// Without a permaddr from netlink the current address is the permanent one unless it was assigned
+	if attrs.PermanentMAC == "" && attrs.Kind == LinkKindPhysical {
+		if assign, ok := sysfsInt(name, "addr_assign_type"); ok && assign == netAddrPermanent {
+			attrs.PermanentMAC = readSysfs(name, "address")
+		}
+	}
+
+	return attrs, nil
+}
+
+// linkKind decides what sort of device an interface is.
+// The netlink info_kind is authoritative; sysfs markers cover hosts without iproute2.
+func linkKind(name string, link ipLink) string {
+	if devType, _ := sysfsInt(name, "type"); devType == arphrdLoopback {
+		return LinkKindLoopback
+	}
+
+	switch kind := link.LinkInfo.InfoKind; kind {
+	case "":
+		// Not known to netlink, look at sysfs below
+	case "tun":
+		var data struct {
+			Type string `json:"type"`
+		}
+		json.Unmarshal(link.LinkInfo.InfoData, &data)
+		if data.Type == "tap" {
+			return LinkKindTap
+		}
+		return LinkKindTun
+	case "macvtap":
+		return LinkKindMacvlan
+	case LinkKindBridge, LinkKindBond, LinkKindVLAN, LinkKindVeth, LinkKindWireGuard,
+		LinkKindMacvlan, LinkKindVXLAN, LinkKindDummy:
+		return kind
+	default:
+		// Other software kinds (ipvlan, gre, ...) keep their name in Driver
+		return LinkKindVirtual
+	}
+
+	dir := filepath.Join(sysClassNet, name)
+	switch {
+	case exists(filepath.Join(dir, "bridge")):
+		return LinkKindBridge
+	case exists(filepath.Join(dir, "bonding")):
+		return LinkKindBond
+	case exists(filepath.Join(dir, "tun_flags")):
+		if devType, _ := sysfsInt(name, "type"); devType == arphrdEther {
+			return LinkKindTap
+		}
+		return LinkKindTun
+	}
+
+	for _, line := range strings.Split(readSysfs(name, "uevent"), "\n") {
+		if devType, ok := strings.CutPrefix(line, "DEVTYPE="); ok {
+			switch devType {
+			case LinkKindVLAN, LinkKindWireGuard, LinkKindVXLAN, LinkKindMacvlan:
+				return devType
+			case "wlan", "wwan":
+				return LinkKindPhysical
+			}
+		}
+	}
+
+	if exists(filepath.Join(dir, "device")) {
+		return LinkKindPhysical
+	}
+	return LinkKindVirtual
+}
+
+// linkDriver names the kernel driver of the device
+func linkDriver(name string, link ipLink) string {
+	if driver := readLink(filepath.Join(sysClassNet, name, "device", "driver")); driver != "" {
+		return filepath.Base(driver)
+	}
+	// Software devices are their own driver, as ethtool -i reports them
+	return link.LinkInfo.InfoKind
+}
+
+// readLink resolves a symlink, returning "" when it does not exist
+func readLink(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return target
+}
+
+// exists reports whether path exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// attachLinkAttributes fills in the link details of each interface on Linux
+func attachLinkAttributes(interfaces []InterfaceInfo) {
+	if !utils.IsLinux() {
+		return
+	}
+	links := readIPLinks()
+	for i := range interfaces {
+		link, ok := links[interfaces[i].Name]
+		if attrs, err := linkAttributes(interfaces[i].Name, link, ok); err == nil {
+			interfaces[i].Link = attrs
+		}
+	}
+}
+
+// printLinkAttributes draws the link details table
+func printLinkAttributes(interfaces []InterfaceInfo) {
+	var tableData [][]string
+	for _, iface := range interfaces {
+		link := iface.Link
+		if link == nil {
+			continue
+		}
+
+		admin := display.Error("down")
+		if link.AdminUp {
+			admin = display.Success("up")
+		}
+		oper := link.OperState
+		switch oper {
+		case "up":
+			oper = display.Success(oper)
+		case "down", "lowerlayerdown":
+			oper = display.Error(oper)
+		}
+		carrier := "-"
+		if link.Carrier != nil {
+			carrier = display.Error("no")
+			if *link.Carrier {
+				carrier = display.Success("yes")
+			}
+		}
+		speed := "-"
+		if link.SpeedMbps > 0 {
+			speed = formatLinkSpeed(link.SpeedMbps)
+			if link.Duplex != "" {
+				speed += " " + link.Duplex
+			}
+		}
+
+		row := []string{
+			iface.Name,
+			link.Kind,
+			valueOrDash(link.Driver),
+			admin + " / " + oper,
+			carrier,
+			speed,
+			fmt.Sprintf("%d", link.TxQueueLen),
+			valueOrDash(link.Master),
+			valueOrDash(link.PermanentMAC),
+		}
+		tableData = append(tableData, row)
+	}
+
+	if len(tableData) == 0 {
+		return
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Link Attributes"
+	tableConfig.Headers = []string{"Interface", "Kind", "Driver", "Admin / Oper", "Carrier", "Speed", "TX Queue", "Master", "Permanent MAC"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 30
+
+	display.PrintTable(tableConfig)
+}
+
+// formatLinkSpeed formats a speed given in Mbit/s
+func formatLinkSpeed(mbps int) string {
+	if mbps >= 1000 && mbps%1000 == 0 {
+		return fmt.Sprintf("%dG", mbps/1000)
+	}
+	if mbps >= 1000 {
+		return fmt.Sprintf("%.1fG", float64(mbps)/1000)
+	}
+	return fmt.Sprintf("%dM", mbps)
+}
@@ -0,0 +1,337 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+// NAT behaviour classifications (RFC 4787 / RFC 5780 terminology)
+const (
+	NATNone                 = "none"
+	NATEndpointIndependent  = "endpoint-independent"
+	NATAddressDependent     = "address-dependent"
+	NATAddressPortDependent = "address-and-port-dependent" // "symmetric" in RFC 3489
+	NATUnknown              = "unknown"
+	NATUDPBlocked           = "udp-blocked"
+)
+
+// idle periods used to probe how long a NAT mapping survives
+var natLifetimeProbes = []time.Duration{
+	5 * time.Second,
+	15 * time.Second,
+	30 * time.Second,
+	60 * time.Second,
+	120 * time.Second,
+}
+
+// NATInfo holds the result of NAT type detection
+type NATInfo struct {
+	Server          string        `json:"server"`
+	LocalAddresses  []string      `json:"local_addresses"`
+	MappedAddress   string        `json:"mapped_address"`
+	PublicIP        string        `json:"public_ip"`
+	Mapping         string        `json:"mapping"`
+	Filtering       string        `json:"filtering"`
+	Type            string        `json:"type"`
+	CGNAT           bool          `json:"cgnat"`
+	CGNATAddresses  []string      `json:"cgnat_addresses"`
+	RFC5780         bool          `json:"rfc5780"` // server advertised OTHER-ADDRESS
+	MappingLifetime time.Duration `json:"mapping_lifetime"`
+	LifetimeBound   string        `json:"lifetime_bound"` // "<", ">=" or "" when not measured
+	Notes           []string      `json:"notes"`
+}
+
+// natServers returns the STUN servers used for behaviour tests
+func natServers() []string {
+	cfg := utils.GetConfig()
+	if len(cfg.NATServers) > 0 {
+		return cfg.NATServers
+	}
+	return cfg.STUNServers
+}
+
+// DetectNAT classifies the NAT between this host and the internet over IPv4
+func DetectNAT(measureLifetime bool) (*NATInfo, error) {
+	info := &NATInfo{
+		Mapping:   NATUnknown,
+		Filtering: NATUnknown,
+		Type:      NATUnknown,
+	}
+
+	// Local addresses, and any in the shared address space used by carrier-grade NAT
+	localIPs, err := GetLocalIPs()
+	if err != nil {
+		return nil, err
+	}
+	local := make(map[string]bool)
+	for _, ipInfo := range localIPs {
+		for _, ip := range ipInfo.LocalIPs {
+			if ipFamily(ip) != "IPv4" {
+				continue
+			}
+			local[ip] = true
+			info.LocalAddresses = append(info.LocalAddresses, ip)
+			if isCGNATAddress(ip) {
+				info.CGNATAddresses = append(info.CGNATAddresses, ip)
+			}
+		}
+	}
+	if gateway, err := GetDefaultGateway("IPv4"); err == nil && isCGNATAddress(gateway.Gateway) {
+		info.CGNATAddresses = append(info.CGNATAddresses, gateway.Gateway)
+	}
+	info.CGNAT = len(info.CGNATAddresses) > 0
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, utils.WrapError(err, "Failed to open UDP socket", utils.ErrorTypeNetwork)
+	}
+	defer conn.Close()
+
+	// Test I: plain binding request to the first reachable server
+	var server *net.UDPAddr
+	var first *STUNResponse
+	for _, candidate := range natServers() {
+		addr, err := net.ResolveUDPAddr("udp4", candidate)
+		if err != nil {
+			info.Notes = append(info.Notes, fmt.Sprintf("%s: %v", candidate, err))
+			continue
+		}
+		response, err := STUNBinding(conn, addr, false, false, utils.STUNTimeout)
+		if err != nil {
+			info.Notes = append(info.Notes, fmt.Sprintf("%s: %v", candidate, err))
+			continue
+		}
+		info.Server = candidate
+		server, first = addr, response
+		break
+	}
+
+	if first == nil {
+		// No STUN answer; fall back to the HTTP/DNS consensus to at least tell NAT from no NAT
+		info.Type = NATUDPBlocked
+		if report := DiscoverPublicIPs(); report.IPv4 != nil && report.IPv4.IP != "" {
+			info.PublicIP = report.IPv4.IP
+			if local[info.PublicIP] {
+				info.Type = NATNone
+			}
+		}
+		return info, nil
+	}
+
+	info.MappedAddress = first.Mapped.String()
+	info.PublicIP = first.Mapped.IP.String()
+	if local[info.PublicIP] {
+		info.Mapping = NATNone
+		info.Filtering = NATNone
+		info.Type = NATNone
+		return info, nil
+	}
+
+	other := first.OtherAddress
+	info.RFC5780 = other != nil && !other.IP.Equal(server.IP)
+	if info.RFC5780 {
+		info.Mapping = natMappingBehaviour(conn, server, other, first.Mapped)
+		info.Filtering = natFilteringBehaviour(server, &info.Notes)
+	} else {
+		info.Mapping = natMappingFromServers(conn, server, first.Mapped, &info.Notes)
+		info.Notes = append(info.Notes, "server lacks RFC 5780 support; filtering not tested")
+	}
+	info.Type = info.Mapping
+
+	if measureLifetime {
+		info.MappingLifetime, info.LifetimeBound = natMappingLifetime(conn, server, first.Mapped)
+	}
+
+	return info, nil
+}
+
+// natMappingBehaviour runs the RFC 5780 section 4.3 mapping tests
+func natMappingBehaviour(conn *net.UDPConn, server, other, mapped *net.UDPAddr) string {
+	// Test II: alternate IP, primary port
+	altIP := &net.UDPAddr{IP: other.IP, Port: server.Port}
+	response, err := STUNBinding(conn, altIP, false, false, utils.STUNTimeout)
+	if err != nil {
+		return NATUnknown
+	}
+	if sameUDPAddr(response.Mapped, mapped) {
+		return NATEndpointIndependent
+	}
+
+	// Test III: alternate IP and alternate port
+	second := response.Mapped
+	response, err = STUNBinding(conn, other, false, false, utils.STUNTimeout)
+	if err != nil {
+		return NATUnknown
+	}
+	if sameUDPAddr(response.Mapped, second) {
+		return NATAddressDependent
+	}
+	return NATAddressPortDependent
+}
+
+// natFilteringBehaviour runs the RFC 5780 section 4.4 filtering tests on a fresh socket.
+// A reply only counts when it came from the address the CHANGE-REQUEST asked for:
+// servers that ignore the request answer from their primary address, which any NAT lets through.
+func natFilteringBehaviour(server *net.UDPAddr, notes *[]string) string {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return NATUnknown
+	}
+	defer conn.Close()
+
+	// Establish the mapping with the primary address first
+	if _, err := STUNBinding(conn, server, false, false, utils.STUNTimeout); err != nil {
+		return NATUnknown
+	}
+
+	// Test II: ask for the answer from the alternate IP and port
+	if response, err := STUNBinding(conn, server, true, true, utils.STUNTimeout); err == nil {
+		if !changedOrigin(response, server, true) {
+			*notes = append(*notes, fmt.Sprintf("filtering test II answered from %s; the server ignored CHANGE-REQUEST", response.From))
+			return NATUnknown
+		}
+		return NATEndpointIndependent
+	}
+
+	// Test III: ask for the answer from the alternate port only
+	if response, err := STUNBinding(conn, server, false, true, utils.STUNTimeout); err == nil {
+		if !changedOrigin(response, server, false) {
+			*notes = append(*notes, fmt.Sprintf("filtering test III answered from %s; the server ignored CHANGE-REQUEST", response.From))
+			return NATUnknown
+		}
+		return NATAddressDependent
+	}
+	return NATAddressPortDependent
+}
+
+// changedOrigin reports whether a response came from a different port of server and,
+// when changeIP is set, also from a different IP; otherwise from the same IP.
+// RESPONSE-ORIGIN, when present, must agree with the packet's source.
+func changedOrigin(response *STUNResponse, server *net.UDPAddr, changeIP bool) bool {
+	for _, origin := range []*net.UDPAddr{response.From, response.ResponseOrigin} {
+		if origin == nil {
+			continue
+		}
+		if origin.Port == server.Port || origin.IP.Equal(server.IP) == changeIP {
+			return false
+		}
+	}
+	return true
+}
+
+
+// natMappingFromServers compares the mapping seen by two different STUN servers when
+// no RFC 5780 server is available. It can only tell endpoint-independent from dependent
+// mapping, so a dependent one stays unknown with a note.
+func natMappingFromServers(conn *net.UDPConn, server, mapped *net.UDPAddr, notes *[]string) string {
+	for _, candidate := range utils.GetConfig().STUNServers {
+		addr, err := net.ResolveUDPAddr("udp4", candidate)
+		if err != nil || addr.IP.Equal(server.IP) {
+			continue
+		}
+		response, err := STUNBinding(conn, addr, false, false, utils.STUNTimeout)
+		if err != nil {
+			continue
+		}
+		if sameUDPAddr(response.Mapped, mapped) {
+			return NATEndpointIndependent
+		}
+		*notes = append(*notes, fmt.Sprintf("%s saw %s: mapping is address- or address-and-port-dependent, which only RFC 5780 tests can tell apart", candidate, response.Mapped))
+		return NATUnknown
+	}
+	return NATUnknown
+}
+
+// natMappingLifetime idles for increasing periods and checks whether the mapping survived
+func natMappingLifetime(conn *net.UDPConn, server, mapped *net.UDPAddr) (time.Duration, string) {
+	var survived time.Duration
+	for _, idle := range natLifetimeProbes {
+		display.PrintInfo(fmt.Sprintf("Idling %s to test mapping lifetime...", idle))
+		time.Sleep(idle)
+
+		response, err := STUNBinding(conn, server, false, false, utils.STUNTimeout)
+		if err != nil || !sameUDPAddr(response.Mapped, mapped) {
+			return idle, "<"
+		}
+		survived = idle
+	}
+	return survived, ">="
+}
+
+// sameUDPAddr compares two transport addresses
+func sameUDPAddr(a, b *net.UDPAddr) bool {
+	return a != nil && b != nil && a.IP.Equal(b.IP) && a.Port == b.Port
+}
+
+// isCGNATAddress reports whether addr is in the RFC 6598 shared address space 100.64.0.0/10
+func isCGNATAddress(addr string) bool {
+	return ClassifyIP(addr).Class == IPClassCGNAT
+}
+
+// ShowNATType displays the NAT type detection results
+func ShowNATType(measureLifetime bool) error {
+	display.PrintInfo("Detecting NAT type...")
+
+	info, err := DetectNAT(measureLifetime)
+	if err != nil {
+		display.PrintError(fmt.Sprintf("NAT detection failed: %v", err))
+		return err
+	}
+
+	natType := info.Type
+	switch info.Type {
+	case NATNone, NATEndpointIndependent:
+		natType = display.Success(info.Type)
+	case NATAddressPortDependent, NATUDPBlocked:
+		natType = display.Error(info.Type)
+	default:
+		natType = display.Warning(info.Type)
+	}
+
+	details := map[string]string{
+		"NAT Type":        natType,
+		"Mapping":         info.Mapping,
+		"Filtering":       info.Filtering,
+		"STUN Server":     info.Server,
+		"Mapped Address":  info.MappedAddress,
+		"Public IP":       info.PublicIP,
+		"Local Addresses": fmt.Sprintf("%v", info.LocalAddresses),
+		"CGNAT":           yesNo(info.CGNAT),
+		"RFC 5780 Tests":  yesNo(info.RFC5780),
+	}
+	if info.CGNAT {
+		details["CGNAT"] = display.Warning(fmt.Sprintf("Yes (%v in 100.64.0.0/10)", info.CGNATAddresses))
+	}
+	if info.LifetimeBound != "" {
+		details["Mapping Lifetime"] = fmt.Sprintf("%s %s", info.LifetimeBound, info.MappingLifetime)
+	}
+
+	display.PrintKeyValue(details, "NAT Detection")
+
+	for _, note := range info.Notes {
+		display.PrintWarning(note)
+	}
+
+	// What this means for VoIP and peer-to-peer traffic
+	switch info.Type {
+	case NATNone:
+		display.PrintSuccess("No NAT - peers can reach this host directly (subject to firewalls)")
+	case NATEndpointIndependent:
+		display.PrintSuccess("Endpoint-independent mapping - UDP hole punching works well")
+	case NATAddressDependent:
+		display.PrintWarning("Address-dependent mapping - hole punching usually works, some peers may need a relay")
+	case NATAddressPortDependent:
+		display.PrintError("Address- and port-dependent mapping (symmetric NAT) - peer-to-peer media will usually need a TURN relay")
+	case NATUDPBlocked:
+		display.PrintError("No STUN response - outbound UDP appears to be blocked")
+	}
+	if info.CGNAT {
+		display.PrintWarning("Carrier-grade NAT detected - inbound port forwarding is not possible")
+	}
+
+	return nil
+}
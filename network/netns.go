@@ -0,0 +1,248 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+// where "ip netns add" bind-mounts named namespaces
+const netnsDir = "/var/run/netns"
+
+// prefix of a --netns target that names a process rather than a namespace
+const namespacePIDPrefix = "pid:"
+
+// Namespace is a network namespace, named or only held by processes
+type Namespace struct {
+	Name      string `json:"name,omitempty"`    // from /var/run/netns
+	ID        string `json:"id,omitempty"`      // e.g. net:[4026531840]
+	PID       int    `json:"pid,omitempty"`     // lowest PID inside
+	Process   string `json:"process,omitempty"` // command name of PID
+	Processes int    `json:"processes"`
+	Current   bool   `json:"current"`
+
+	info os.FileInfo // the nsfs file, to compare namespaces
+}
+
+// Target returns what --netns accepts for this namespace
+func (n Namespace) Target() string {
+	if n.Name != "" {
+		return n.Name
+	}
+	return namespacePIDPrefix + strconv.Itoa(n.PID)
+}
+
+// ListNamespaces returns the named namespaces and every namespace a process lives in
+func ListNamespaces() ([]Namespace, error) {
+	if !utils.IsLinux() {
+		return nil, fmt.Errorf("network namespaces are only available on Linux")
+	}
+
+	current, _ := os.Readlink("/proc/self/ns/net")
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, utils.WrapError(err, "Failed to read /proc", utils.ErrorTypePermission)
+	}
+
+	byID := make(map[string]*Namespace)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// Kernel threads have no command line and all sit in the initial namespace
+		if readProcFile(filepath.Join("/proc", entry.Name(), "cmdline")) == "" {
+			continue
+		}
+		path := filepath.Join("/proc", entry.Name(), "ns", "net")
+		// Other users' processes are unreadable without privileges
+		id, err := os.Readlink(path)
+		if err != nil {
+			continue
+		}
+
+		ns, ok := byID[id]
+		if !ok {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			ns = &Namespace{ID: id, PID: pid, Current: id == current, info: info}
+			ns.Process = readProcFile(filepath.Join("/proc", entry.Name(), "comm"))
+			byID[id] = ns
+		}
+		ns.Processes++
+	}
+
+	var namespaces []Namespace
+	named := make(map[string]bool)
+	if files, err := os.ReadDir(netnsDir); err == nil {
+		for _, file := range files {
+			info, err := os.Stat(filepath.Join(netnsDir, file.Name()))
+			if err != nil {
+				continue
+			}
+			ns := Namespace{Name: file.Name(), info: info}
+			for id, owned := range byID {
+				if os.SameFile(info, owned.info) {
+					owned.Name = file.Name()
+					ns = *owned
+					named[id] = true
+					break
+				}
+			}
+			namespaces = append(namespaces, ns)
+		}
+	}
+	for id, ns := range byID {
+		if !named[id] {
+			namespaces = append(namespaces, *ns)
+		}
+	}
+
+	// Current first, then named ones, then by PID
+	sort.Slice(namespaces, func(i, j int) bool {
+		a, b := namespaces[i], namespaces[j]
+		if a.Current != b.Current {
+			return a.Current
+		}
+		if (a.Name != "") != (b.Name != "") {
+			return a.Name != ""
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.PID < b.PID
+	})
+	return namespaces, nil
+}
+
+// readProcFile returns the trimmed content of a small /proc file
+func readProcFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// NamespaceCommand builds the command that runs netinfo with args inside the
+// namespace target: a name from /var/run/netns, "pid:<pid>", or a bare PID when
+// no namespace has that name.
+// Named namespaces go through "ip netns exec", which also mounts the namespace's
+// /sys and /etc/netns/<name> files. For a PID, nsenter joins the namespace and
+// a private mount namespace gets a fresh /sys so /sys/class/net shows its devices.
+func NamespaceCommand(target string, args []string) (*exec.Cmd, error) {
+	if !utils.IsLinux() {
+		return nil, fmt.Errorf("network namespaces are only available on Linux")
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	// A named namespace may be all digits ("ip netns add 100"), so names win over bare PIDs
+	pidText, isPID := strings.CutPrefix(target, namespacePIDPrefix)
+	if !isPID {
+		if _, err := os.Stat(filepath.Join(netnsDir, target)); err != nil {
+			if _, err := strconv.Atoi(target); err == nil {
+				pidText, isPID = target, true
+			}
+		}
+	}
+
+	var cmd *exec.Cmd
+	if isPID {
+		pid, err := strconv.Atoi(pidText)
+		if err != nil || pid <= 0 {
+			return nil, utils.WrapError(nil, fmt.Sprintf("invalid PID %q", pidText), utils.ErrorTypeValidation)
+		}
+		path := fmt.Sprintf("/proc/%d/ns/net", pid)
+		if _, err := os.Stat(path); err != nil {
+			return nil, utils.WrapError(err, fmt.Sprintf("no network namespace for PID %d", pid), utils.ErrorTypeValidation)
+		}
+		remount := `umount -l /sys 2>/dev/null; mount -t sysfs sysfs /sys && exec "$0" "$@"`
+		cmdArgs := []string{"--net=" + path, "--", "unshare", "--mount", "--propagation", "private", "--", "sh", "-c", remount, self}
+		cmd = exec.Command("nsenter", append(cmdArgs, args...)...)
+	} else {
+		if _, err := os.Stat(filepath.Join(netnsDir, target)); err != nil {
+			return nil, utils.WrapError(err, fmt.Sprintf("network namespace %s not found", target), utils.ErrorTypeValidation)
+		}
+		cmd = exec.Command("ip", append([]string{"netns", "exec", target, self}, args...)...)
+	}
+
+	cmd.Env = append(os.Environ(), utils.NetnsEnvVar+"="+target)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd, nil
+}
+
+// RunInNamespace runs netinfo with args inside target and returns its exit code.
+// Entering a namespace needs root (CAP_SYS_ADMIN).
+func RunInNamespace(target string, args []string) (int, error) {
+	cmd, err := NamespaceCommand(target, args)
+	if err != nil {
+		return 1, err
+	}
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), nil
+		}
+		return 1, utils.WrapError(err, "Failed to enter network namespace", utils.ErrorTypeCommand)
+	}
+	return 0, nil
+}
+
+// ShowNamespaces lists the network namespaces
+func ShowNamespaces() error {
+	namespaces, err := ListNamespaces()
+	if err != nil {
+		display.PrintError(utils.GetUserFriendlyMessage(err))
+		return err
+	}
+
+	var tableData [][]string
+	for _, ns := range namespaces {
+		name := valueOrDash(ns.Name)
+		if ns.Current {
+			name = display.Success(name + " (current)")
+		}
+		pid, process := "-", "-"
+		if ns.PID != 0 {
+			pid = strconv.Itoa(ns.PID)
+			process = valueOrDash(ns.Process)
+		}
+		row := []string{
+			name,
+			valueOrDash(ns.ID),
+			pid,
+			process,
+			fmt.Sprintf("%d", ns.Processes),
+			ns.Target(),
+		}
+		tableData = append(tableData, row)
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Network Namespaces"
+	tableConfig.Headers = []string{"Name", "ID", "PID", "Process", "Processes", "Target"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 40
+
+	display.PrintTable(tableConfig)
+
+	if os.Geteuid() != 0 {
+		display.PrintWarning("Not running as root: namespaces of other users' processes are missing and entering one will fail")
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package network
 import (
 	"context"
 	"fmt"
+	"net"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -16,6 +17,7 @@ import (
 // PingResult holds ping test results
 type PingResult struct {
 	Host        string        `json:"host"`
+	HostName    string        `json:"host_name,omitempty"` // PTR name when the host is an IP
 	Success     bool          `json:"success"`
 	PacketLoss  float64       `json:"packet_loss"`
 	MinRTT      time.Duration `json:"min_rtt"`
@@ -88,6 +90,11 @@ func ShowPingTest() error {
 		return err
 	}
 	
+	// Add PTR name for IP targets
+	if ResolveNamesEnabled() {
+		enrichPingNames([]*PingResult{result})
+	}
+	
 	// Display results
 	displayPingResult(result)
 	
@@ -269,7 +276,7 @@ func displayPingResult(result *PingResult) {
 	
 	// Create summary table
 	summaryData := map[string]string{
-		"Host":         result.Host,
+		"Host":         withName(result.Host, result.HostName),
 		"Packets Sent": fmt.Sprintf("%d", result.PacketsSent),
 		"Packets Received": fmt.Sprintf("%d", result.PacketsRecv),
 		"Packet Loss":  fmt.Sprintf("%.1f%%", result.PacketLoss),
@@ -306,6 +313,19 @@ func displayPingResult(result *PingResult) {
 	}
 }
 
+// enrichPingNames fills the PTR names of ping targets given as IP addresses
+func enrichPingNames(results []*PingResult) {
+	var addrs []string
+	for _, result := range results {
+		addrs = append(addrs, result.Host)
+	}
+	
+	names := LookupPTRs(addrs, utils.PTRLookupDeadline)
+	for _, result := range results {
+		result.HostName = names[result.Host]
+	}
+}
+
 // QuickPing performs a quick ping test with default settings
 func QuickPing(host string) (*PingResult, error) {
 	config := DefaultPingConfig(host)
@@ -361,6 +381,11 @@ func ShowPingMultipleHosts() error {
 		return err
 	}
 	
+	// Add PTR names for IP targets
+	if ResolveNamesEnabled() {
+		enrichPingNames(results)
+	}
+	
 	// Create summary table
 	var tableData [][]string
 	for _, result := range results {
@@ -375,7 +400,7 @@ func ShowPingMultipleHosts() error {
 		avgRTT := utils.FormatDuration(result.AvgRTT)
 		
 		row := []string{
-			result.Host,
+			withName(result.Host, result.HostName),
 			status,
 			packetLoss,
 			avgRTT,
@@ -426,11 +451,7 @@ func TestConnectivity() error {
 	
 	// Test DNS servers
 	display.PrintInfo("3. Testing DNS connectivity...")
-	dnsConfig, err := getWindowsDNS()
-	if utils.IsLinux() {
-		dnsConfig, err = getLinuxDNS()
-	}
-	
+	dnsConfig, err := getDNSConfig()
 	if err == nil && len(dnsConfig.Servers) > 0 {
 		for _, dnsInfo := range dnsConfig.Servers {
 			if len(dnsInfo.IPv4) > 0 {
@@ -454,6 +475,48 @@ func TestConnectivity() error {
 		display.PrintSuccess(fmt.Sprintf("Internet connectivity: OK (%s)", utils.FormatDuration(internetResult.AvgRTT)))
 	}
 	
+	// Check DNS answers for hijacking
+	display.PrintInfo("5. Checking DNS answers for tampering...")
+	if dnsConfig != nil && len(dnsConfig.Servers) > 0 {
+		for _, result := range CheckDNSIntegrity(uniqueDNSServers(dnsConfig)) {
+			if result.Reference {
+				continue
+			}
+			switch result.Verdict {
+			case DNSVerdictClean:
+				display.PrintSuccess(fmt.Sprintf("DNS server %s: answers consistent", result.Server))
+			case DNSVerdictHijacked:
+				display.PrintError(fmt.Sprintf("DNS server %s: answers tampered (NXDOMAIN rewriting or private answers)", result.Server))
+			case DNSVerdictInconsistent:
+				display.PrintWarning(fmt.Sprintf("DNS server %s: answers differ from reference for %s", result.Server, strings.Join(result.Mismatches, ", ")))
+			default:
+				display.PrintWarning(fmt.Sprintf("DNS server %s: not reachable", result.Server))
+			}
+		}
+	} else {
+		display.PrintWarning("No DNS servers to check")
+	}
+	
+	// IPv6 readiness and which family wins a Happy Eyeballs race
+	host, port, err := net.SplitHostPort(utils.GetConfig().DualStackTarget)
+	if err != nil {
+		host, port = utils.GetConfig().DualStackTarget, "443"
+	}
+	display.PrintInfo(fmt.Sprintf("6. Checking IPv6 readiness against %s...", net.JoinHostPort(host, port)))
+	dualStack, err := CheckDualStack(host, port)
+	if err != nil {
+		display.PrintWarning(fmt.Sprintf("Dual-stack check failed: %v", err))
+	} else {
+		printDualStackChecks(dualStack)
+		
+		display.PrintInfo("7. Racing IPv4 and IPv6 connections (Happy Eyeballs)...")
+		if race := dualStack.Race; race.Winner != "" {
+			display.PrintSuccess(fmt.Sprintf("%s wins via %s after %s", race.Winner, race.WinnerAddr, utils.FormatDuration(race.Elapsed)))
+		} else {
+			display.PrintError("No connection could be established")
+		}
+	}
+	
 	display.PrintSeparator()
 	display.PrintSuccess("Connectivity test completed")
 	
@@ -0,0 +1,274 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"netinfo/display"
+	"netinfo/utils"
+
+	"github.com/miekg/dns"
+)
+
+// PropagationAnswer holds what a single server returns for the checked record
+type PropagationAnswer struct {
+	Server        string   `json:"server"`
+	Host          string   `json:"host"` // NS host name for authoritative servers
+	Authoritative bool     `json:"authoritative"`
+	Values        []string `json:"values"`
+	TTL           uint32   `json:"ttl"`
+	Serial        uint32   `json:"serial"`
+	Rcode         string   `json:"rcode"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// PropagationResult holds the propagation state of one record
+type PropagationResult struct {
+	Name       string              `json:"name"`
+	Type       string              `json:"type"`
+	Zone       string              `json:"zone"`
+	Answers    []PropagationAnswer `json:"answers"`
+	Consistent bool                `json:"consistent"`
+}
+
+// CheckPropagation queries the zone's authoritative name servers and extra resolvers in parallel
+func CheckPropagation(name string, qtype uint16, resolvers []string) (*PropagationResult, error) {
+	name = dns.CanonicalName(name)
+	result := &PropagationResult{
+		Name: name,
+		Type: dns.TypeToString[qtype],
+	}
+
+	dnsConfig, err := getDNSConfig()
+	if err != nil {
+		return nil, err
+	}
+	servers := uniqueDNSServers(dnsConfig)
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("%s", utils.MsgNoDNSServers)
+	}
+
+	// Find the zone apex and its NS set through the local resolver
+	zones, err := findZoneCuts(servers[0], name)
+	if err != nil {
+		return nil, err
+	}
+	result.Zone = zones[len(zones)-1]
+
+	var targets []PropagationAnswer
+	nsSet, _, err := fetchRRset(servers[0], result.Zone, dns.TypeNS)
+	if err != nil {
+		return nil, err
+	}
+	for _, rr := range nsSet {
+		ns, ok := rr.(*dns.NS)
+		if !ok {
+			continue
+		}
+		addresses := lookupAddresses(servers[0], ns.Ns).Addresses
+		if len(addresses) == 0 {
+			targets = append(targets, PropagationAnswer{Host: ns.Ns, Authoritative: true, Error: "name server address not found"})
+			continue
+		}
+		for _, addr := range addresses {
+			targets = append(targets, PropagationAnswer{Server: addr, Host: ns.Ns, Authoritative: true})
+		}
+	}
+	for _, resolver := range resolvers {
+		targets = append(targets, PropagationAnswer{Server: resolver, Host: resolver})
+	}
+
+	var wg sync.WaitGroup
+	for i := range targets {
+		if targets[i].Server == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(answer *PropagationAnswer) {
+			defer wg.Done()
+			queryPropagation(answer, name, result.Zone, qtype)
+		}(&targets[i])
+	}
+	wg.Wait()
+
+	result.Answers = targets
+	result.Consistent = propagationConsistent(targets)
+
+	return result, nil
+}
+
+// queryPropagation fills answer with the record values and zone serial seen by one server
+func queryPropagation(answer *PropagationAnswer, name, zone string, qtype uint16) {
+	resp, err := queryDNSDirect(answer.Server, name, qtype, !answer.Authoritative)
+	if err != nil {
+		answer.Error = err.Error()
+		return
+	}
+
+	answer.Rcode = dns.RcodeToString[resp.Rcode]
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype != qtype {
+			continue
+		}
+		// Strip the header so only the record data is compared
+		value := strings.TrimPrefix(rr.String(), rr.Header().String())
+		answer.Values = append(answer.Values, value)
+		answer.TTL = rr.Header().Ttl
+	}
+	sort.Strings(answer.Values)
+
+	resp, err = queryDNSDirect(answer.Server, zone, dns.TypeSOA, !answer.Authoritative)
+	if err == nil {
+		for _, rr := range resp.Answer {
+			if soa, ok := rr.(*dns.SOA); ok {
+				answer.Serial = soa.Serial
+			}
+		}
+	}
+}
+
+// queryDNSDirect sends a query with recursion desired only when asking a recursive resolver
+func queryDNSDirect(server, name string, qtype uint16, recursive bool) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	msg.RecursionDesired = recursive
+	msg.SetEdns0(4096, false)
+
+	return exchangeDNS(server, msg)
+}
+
+// propagationConsistent reports whether every server that answered returned the same values
+func propagationConsistent(answers []PropagationAnswer) bool {
+	reference := ""
+	first := true
+	for _, answer := range answers {
+		if answer.Error != "" {
+			continue
+		}
+		values := strings.Join(answer.Values, "|")
+		if first {
+			reference = values
+			first = false
+		} else if values != reference {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseRecordType converts a record type name such as "AAAA" to its DNS type code
+func ParseRecordType(name string) (uint16, error) {
+	qtype, ok := dns.StringToType[strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown record type: %s", name)
+	}
+	return qtype, nil
+}
+
+// ShowPropagationTest provides an interactive propagation check
+func ShowPropagationTest() error {
+	display.PrintInfo("DNS Propagation Check")
+	display.PrintSeparator()
+
+	name, err := display.ShowInput("Enter record name", "example.com")
+	if err != nil {
+		display.PrintError(fmt.Sprintf("Input error: %v", err))
+		return err
+	}
+
+	typeName, err := display.ShowInput("Record type", "A")
+	if err != nil {
+		display.PrintError(fmt.Sprintf("Input error: %v", err))
+		return err
+	}
+	qtype, err := ParseRecordType(typeName)
+	if err != nil {
+		display.PrintError(err.Error())
+		return err
+	}
+
+	resolverList, err := display.ShowInput("Extra resolvers (comma separated)", strings.Join(utils.GetConfig().PropagationResolvers, ","))
+	if err != nil {
+		display.PrintError(fmt.Sprintf("Input error: %v", err))
+		return err
+	}
+	var resolvers []string
+	for _, resolver := range strings.Split(resolverList, ",") {
+		if resolver = strings.TrimSpace(resolver); resolver != "" {
+			resolvers = append(resolvers, resolver)
+		}
+	}
+
+	return ShowPropagation(name, qtype, resolvers)
+}
+
+// ShowPropagation displays the propagation state of a record
+func ShowPropagation(name string, qtype uint16, resolvers []string) error {
+	display.PrintInfo(fmt.Sprintf("Checking propagation of %s %s...", name, dns.TypeToString[qtype]))
+
+	result, err := CheckPropagation(name, qtype, resolvers)
+	if err != nil {
+		display.PrintError(fmt.Sprintf("Propagation check failed: %v", err))
+		return err
+	}
+
+	display.PrintSuccess(fmt.Sprintf("Zone %s, %d servers queried", result.Zone, len(result.Answers)))
+
+	// Count how many servers returned each distinct value set
+	valueCount := make(map[string]int)
+	for _, answer := range result.Answers {
+		if answer.Error == "" {
+			valueCount[strings.Join(answer.Values, "|")]++
+		}
+	}
+
+	var tableData [][]string
+	for _, answer := range result.Answers {
+		role := "Resolver"
+		if answer.Authoritative {
+			role = "Authoritative"
+		}
+
+		values := strings.Join(answer.Values, ", ")
+		ttl := fmt.Sprintf("%d", answer.TTL)
+		serial := fmt.Sprintf("%d", answer.Serial)
+		switch {
+		case answer.Error != "":
+			values = display.Error(utils.TruncateString(answer.Error, 40))
+			ttl, serial = "-", "-"
+		case len(answer.Values) == 0:
+			values = display.Warning(answer.Rcode)
+			ttl = "-"
+		case !result.Consistent && valueCount[strings.Join(answer.Values, "|")] == 1:
+			values = display.Warning(values)
+		}
+
+		row := []string{
+			answer.Host,
+			answer.Server,
+			role,
+			values,
+			ttl,
+			serial,
+		}
+		tableData = append(tableData, row)
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = fmt.Sprintf("Propagation of %s %s", result.Name, result.Type)
+	tableConfig.Headers = []string{"Server", "Address", "Role", "Values", "TTL", "Serial"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+
+	display.PrintTable(tableConfig)
+
+	if result.Consistent {
+		display.PrintSuccess("All servers return the same values")
+	} else {
+		display.PrintWarning(fmt.Sprintf("Servers disagree: %d distinct answers", len(valueCount)))
+	}
+
+	return nil
+}
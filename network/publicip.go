@@ -0,0 +1,317 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"netinfo/utils"
+
+	"github.com/miekg/dns"
+)
+
+// Public IP discovery methods
+const (
+	PublicIPMethodHTTP = "http"
+	PublicIPMethodDNS  = "dns"
+	PublicIPMethodSTUN = "stun"
+)
+
+// PublicIPObservation holds the answer of a single endpoint
+type PublicIPObservation struct {
+	Endpoint string        `json:"endpoint"`
+	Method   string        `json:"method"`
+	Family   string        `json:"family"`
+	IP       string        `json:"ip"`
+	Latency  time.Duration `json:"latency"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// PublicIPResult holds the consensus public address for one address family
+type PublicIPResult struct {
+	Family       string                `json:"family"`
+	IP           string                `json:"ip"`
+	Endpoint     string                `json:"endpoint"` // fastest endpoint that returned the consensus address
+	Method       string                `json:"method"`   // method used by that endpoint
+	Votes        int                   `json:"votes"`
+	Responses    int                   `json:"responses"`
+	Agree        bool                  `json:"agree"`
+	Candidates   map[string]int        `json:"candidates"` // address -> number of endpoints
+	Observations []PublicIPObservation `json:"observations"`
+	Geo          *GeoIPInfo            `json:"geo,omitempty"` // from the local GeoIP databases
+}
+
+// PublicIPReport holds the public addresses of both address families
+type PublicIPReport struct {
+	IPv4 *PublicIPResult `json:"ipv4"`
+	IPv6 *PublicIPResult `json:"ipv6"`
+}
+
+// publicIPProbe is one way of asking for the public address over a given address family
+type publicIPProbe struct {
+	endpoint string
+	method   string
+	query    func(ctx context.Context, family string) (string, error)
+}
+
+// publicIPEndpoints returns the configured endpoint list, or the built-in one
+func publicIPEndpoints() []string {
+	if endpoints := utils.GetConfig().PublicIPEndpoints; len(endpoints) > 0 {
+		return endpoints
+	}
+	return PublicIPEndpoints
+}
+
+// publicIPProbes builds the probes for every enabled discovery method
+func publicIPProbes() []publicIPProbe {
+	cfg := utils.GetConfig()
+
+	var probes []publicIPProbe
+	for _, method := range cfg.PublicIPMethods {
+		switch strings.ToLower(method) {
+		case PublicIPMethodHTTP:
+			for _, endpoint := range publicIPEndpoints() {
+				probes = append(probes, publicIPProbe{
+					endpoint: endpoint,
+					method:   PublicIPMethodHTTP,
+					query: func(ctx context.Context, family string) (string, error) {
+						return fetchPublicIP(ctx, familyHTTPClient(family), endpoint)
+					},
+				})
+			}
+		case PublicIPMethodDNS:
+			for _, query := range cfg.PublicIPDNSQueries {
+				probes = append(probes, publicIPProbe{
+					endpoint: fmt.Sprintf("%s@%s", query.Name, query.Server),
+					method:   PublicIPMethodDNS,
+					query: func(ctx context.Context, family string) (string, error) {
+						return dnsPublicIP(ctx, query, family)
+					},
+				})
+			}
+		case PublicIPMethodSTUN:
+			for _, server := range cfg.STUNServers {
+				probes = append(probes, publicIPProbe{
+					endpoint: "stun:" + server,
+					method:   PublicIPMethodSTUN,
+					query: func(ctx context.Context, family string) (string, error) {
+						return stunPublicIP(ctx, server, family)
+					},
+				})
+			}
+		}
+	}
+	return probes
+}
+
+// DiscoverPublicIPs races all discovery methods over IPv4 and IPv6 and cross-validates the answers by majority
+func DiscoverPublicIPs() *PublicIPReport {
+	ctx, cancel := context.WithTimeout(context.Background(), utils.PublicIPTimout)
+	defer cancel()
+
+	probes := publicIPProbes()
+	report := &PublicIPReport{}
+
+	var wg sync.WaitGroup
+	for _, family := range []string{"IPv4", "IPv6"} {
+		wg.Add(1)
+		go func(family string) {
+			defer wg.Done()
+			result := queryPublicIPFamily(ctx, family, probes)
+			if family == "IPv4" {
+				report.IPv4 = result
+			} else {
+				report.IPv6 = result
+			}
+		}(family)
+	}
+	wg.Wait()
+
+	// Keep every observation for "netinfo ip history"; a read-only home is not an error here
+	recordPublicIPs(report)
+
+	return report
+}
+
+// queryPublicIPFamily runs every probe concurrently over one address family
+func queryPublicIPFamily(ctx context.Context, family string, probes []publicIPProbe) *PublicIPResult {
+	observations := make([]PublicIPObservation, len(probes))
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func(i int, probe publicIPProbe) {
+			defer wg.Done()
+			start := time.Now()
+			ip, err := probe.query(ctx, family)
+
+			observation := PublicIPObservation{
+				Endpoint: probe.endpoint,
+				Method:   probe.method,
+				Family:   family,
+				Latency:  time.Since(start),
+			}
+			switch {
+			case err != nil:
+				observation.Error = err.Error()
+			case ipFamily(ip) != family:
+				observation.Error = fmt.Sprintf("returned %s address %s", ipFamily(ip), ip)
+			default:
+				observation.IP = ip
+			}
+			observations[i] = observation
+		}(i, probe)
+	}
+	wg.Wait()
+
+	result := publicIPConsensus(family, observations)
+	if result.IP != "" && GeoIPAvailable() {
+		result.Geo, _ = LookupGeoIP(result.IP)
+	}
+	return result
+}
+
+// publicIPConsensus picks the address reported by most endpoints
+func publicIPConsensus(family string, observations []PublicIPObservation) *PublicIPResult {
+	result := &PublicIPResult{
+		Family:       family,
+		Candidates:   make(map[string]int),
+		Observations: observations,
+	}
+
+	for _, observation := range observations {
+		if observation.IP != "" {
+			result.Candidates[observation.IP]++
+			result.Responses++
+		}
+	}
+	if result.Responses == 0 {
+		return result
+	}
+
+	// Highest vote wins; ties go to the address seen first by the fastest endpoint
+	sorted := append([]PublicIPObservation{}, observations...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Latency < sorted[j].Latency })
+	for _, observation := range sorted {
+		if observation.IP == "" {
+			continue
+		}
+		if result.Candidates[observation.IP] > result.Votes {
+			result.IP = observation.IP
+			result.Votes = result.Candidates[observation.IP]
+			result.Endpoint = observation.Endpoint
+			result.Method = observation.Method
+		}
+	}
+	result.Agree = len(result.Candidates) == 1
+
+	return result
+}
+
+// familyHTTPClient returns an HTTP client whose connections are forced onto one address family
+func familyHTTPClient(family string) *http.Client {
+	network := "tcp4"
+	if family == "IPv6" {
+		network = "tcp6"
+	}
+
+	dialer := &net.Dialer{Timeout: utils.HTTPTimeout}
+	transport := &http.Transport{
+		// No proxy: through one the services would report the proxy's address
+		Proxy: nil,
+		DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		},
+		TLSHandshakeTimeout: utils.HTTPTimeout,
+	}
+
+	return &http.Client{
+		Timeout:   utils.HTTPTimeout,
+		Transport: transport,
+	}
+}
+
+// fetchPublicIP asks a single endpoint for the caller's address
+func fetchPublicIP(ctx context.Context, client *http.Client, endpoint string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return "", utils.WrapError(err, "Failed to create request", utils.ErrorTypeNetwork)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", utils.WrapError(err, "Request failed", utils.ErrorTypeNetwork)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", utils.WrapError(nil, fmt.Sprintf("HTTP %d", resp.StatusCode), utils.ErrorTypeNetwork)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", utils.WrapError(err, "Failed to read response", utils.ErrorTypeParse)
+	}
+
+	publicIP := strings.TrimSpace(string(body))
+	if net.ParseIP(publicIP) == nil {
+		return "", utils.WrapError(nil, "Invalid IP address received", utils.ErrorTypeParse)
+	}
+
+	return publicIP, nil
+}
+
+// dnsPublicIP asks a name server that reflects the source address of the query
+func dnsPublicIP(ctx context.Context, query utils.PublicIPDNSQuery, family string) (string, error) {
+	qtype := dns.TypeA
+	if family == "IPv6" {
+		qtype = dns.TypeAAAA
+	}
+	if strings.EqualFold(query.Type, "TXT") {
+		qtype = dns.TypeTXT
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(query.Name), qtype)
+	msg.RecursionDesired = false
+
+	client := &dns.Client{Net: udpNetwork(family), Timeout: utils.DNSQueryTimeout}
+	resp, _, err := client.ExchangeContext(ctx, msg, dnsServerAddress(query.Server))
+	if err != nil {
+		return "", utils.WrapError(err, fmt.Sprintf("DNS query to %s failed", query.Server), utils.ErrorTypeNetwork)
+	}
+
+	for _, rr := range resp.Answer {
+		switch record := rr.(type) {
+		case *dns.A:
+			return record.A.String(), nil
+		case *dns.AAAA:
+			return record.AAAA.String(), nil
+		case *dns.TXT:
+			for _, txt := range record.Txt {
+				if net.ParseIP(txt) != nil {
+					return txt, nil
+				}
+			}
+		}
+	}
+
+	return "", utils.WrapError(nil, fmt.Sprintf("%s returned no address (%s)", query.Server, dns.RcodeToString[resp.Rcode]), utils.ErrorTypeParse)
+}
+
+// ipFamily returns "IPv4" or "IPv6" for an address string
+func ipFamily(addr string) string {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return ""
+	}
+	if ip.To4() != nil {
+		return "IPv4"
+	}
+	return "IPv6"
+}
@@ -0,0 +1,117 @@
+package network
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"netinfo/utils"
+
+	"github.com/miekg/dns"
+)
+
+// dnsTestServer serves a fixed set of records on a local UDP socket
+func dnsTestServer(t *testing.T, records map[uint16][]string) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		msg := new(dns.Msg)
+		msg.SetReply(r)
+		question := r.Question[0]
+		for _, text := range records[question.Qtype] {
+			rr, err := dns.NewRR(question.Name + " 60 IN " + dns.TypeToString[question.Qtype] + " " + text)
+			if err != nil {
+				t.Errorf("bad test record %q: %v", text, err)
+				continue
+			}
+			msg.Answer = append(msg.Answer, rr)
+		}
+		if len(msg.Answer) == 0 {
+			msg.Rcode = dns.RcodeNameError
+		}
+		w.WriteMsg(msg)
+	})}
+
+	started := make(chan struct{})
+	server.NotifyStartedFunc = func() { close(started) }
+	go server.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { server.Shutdown() })
+
+	return conn.LocalAddr().String()
+}
+
+func TestDNSPublicIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		records map[uint16][]string
+		query   utils.PublicIPDNSQuery
+		want    string
+		wantErr bool
+	}{
+		{
+			// resolver1.opendns.com style: the A record is the caller's address
+			name:    "A record",
+			records: map[uint16][]string{dns.TypeA: {"198.51.100.20"}},
+			query:   utils.PublicIPDNSQuery{Name: "myip.opendns.com", Type: "A"},
+			want:    "198.51.100.20",
+		},
+		{
+			// ns1.google.com style: the address is one of several TXT strings
+			name: "TXT record",
+			records: map[uint16][]string{dns.TypeTXT: {
+				`"edns0-client-subnet 198.51.100.0/24"`,
+				`"198.51.100.21"`,
+			}},
+			query: utils.PublicIPDNSQuery{Name: "o-o.myaddr.l.google.com", Type: "TXT"},
+			want:  "198.51.100.21",
+		},
+		{
+			name:    "TXT without an address",
+			records: map[uint16][]string{dns.TypeTXT: {`"not an address"`}},
+			query:   utils.PublicIPDNSQuery{Name: "o-o.myaddr.l.google.com", Type: "TXT"},
+			wantErr: true,
+		},
+		{
+			name:    "NXDOMAIN",
+			records: map[uint16][]string{},
+			query:   utils.PublicIPDNSQuery{Name: "myip.opendns.com", Type: "A"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Server = dnsTestServer(t, tt.records)
+
+			ip, err := dnsPublicIP(context.Background(), tt.query, "IPv4")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", ip)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("dnsPublicIP: %v", err)
+			}
+			if ip != tt.want {
+				t.Errorf("dnsPublicIP = %q, want %q", ip, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNSPublicIPCancelled(t *testing.T) {
+	server := dnsTestServer(t, map[uint16][]string{dns.TypeA: {"198.51.100.20"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	query := utils.PublicIPDNSQuery{Server: server, Name: "myip.opendns.com", Type: "A"}
+	if _, err := dnsPublicIP(ctx, query, "IPv4"); err == nil {
+		t.Error("expected an error with a cancelled context")
+	}
+}
@@ -0,0 +1,202 @@
+package network
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"netinfo/utils"
+
+	"github.com/miekg/dns"
+)
+
+// maximum number of PTR lookups in flight at once
+const ptrWorkers = 16
+
+// ptrEntry is a cached reverse lookup result; an empty name caches a failed lookup
+type ptrEntry struct {
+	name    string
+	expires time.Time
+}
+
+// PTRCache is a TTL-aware reverse DNS cache shared by all views
+type PTRCache struct {
+	mu       sync.Mutex
+	entries  map[string]ptrEntry
+	inflight map[string]chan struct{}
+}
+
+var (
+	ptrCache = &PTRCache{
+		entries:  make(map[string]ptrEntry),
+		inflight: make(map[string]chan struct{}),
+	}
+	resolveNames atomic.Bool
+)
+
+// SetResolveNames enables or disables reverse DNS enrichment in the views
+func SetResolveNames(enabled bool) {
+	resolveNames.Store(enabled)
+}
+
+// ResolveNamesEnabled reports whether views should show PTR names
+func ResolveNamesEnabled() bool {
+	return resolveNames.Load()
+}
+
+// LookupPTRs resolves the PTR names of addrs concurrently and returns those known before the deadline.
+// Lookups still running at the deadline keep going in the background and land in the cache.
+func LookupPTRs(addrs []string, deadline time.Duration) map[string]string {
+	names := make(map[string]string)
+
+	var pending []string
+	seen := make(map[string]bool)
+	for _, addr := range addrs {
+		if net.ParseIP(addr) == nil || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		if name, ok := ptrCache.get(addr); ok {
+			if name != "" {
+				names[addr] = name
+			}
+			continue
+		}
+		pending = append(pending, addr)
+	}
+	if len(pending) == 0 {
+		return names
+	}
+
+	timeout := time.NewTimer(deadline)
+	defer timeout.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Reading the DNS configuration can be slow too (PowerShell on Windows), so it counts against the deadline
+		server := ""
+		if dnsConfig, err := getDNSConfig(); err == nil {
+			if servers := uniqueDNSServers(dnsConfig); len(servers) > 0 {
+				server = servers[0]
+			}
+		}
+
+		sem := make(chan struct{}, ptrWorkers)
+		var wg sync.WaitGroup
+		for _, addr := range pending {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(addr string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				ptrCache.resolve(server, addr)
+			}(addr)
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-timeout.C:
+	}
+
+	for _, addr := range pending {
+		if name, ok := ptrCache.get(addr); ok && name != "" {
+			names[addr] = name
+		}
+	}
+	return names
+}
+
+// get returns a cached, unexpired PTR name
+func (c *PTRCache) get(addr string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[addr]
+	if !ok || time.Now().After(entry.expires) {
+		return "", false
+	}
+	return entry.name, true
+}
+
+// resolve looks up addr once even when several views ask for it at the same time
+func (c *PTRCache) resolve(server, addr string) {
+	c.mu.Lock()
+	if wait, ok := c.inflight[addr]; ok {
+		c.mu.Unlock()
+		<-wait
+		return
+	}
+	wait := make(chan struct{})
+	c.inflight[addr] = wait
+	c.mu.Unlock()
+
+	name, ttl := lookupPTR(server, addr)
+
+	c.mu.Lock()
+	c.entries[addr] = ptrEntry{name: name, expires: time.Now().Add(ttl)}
+	delete(c.inflight, addr)
+	c.mu.Unlock()
+	close(wait)
+}
+
+// lookupPTR queries the PTR record for addr and returns it with its TTL
+func lookupPTR(server, addr string) (string, time.Duration) {
+	reverse, err := dns.ReverseAddr(addr)
+	if err != nil {
+		return "", utils.PTRNegativeTTL
+	}
+
+	// Without a configured server fall back to the system resolver, which hides the TTL
+	if server == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), utils.DNSQueryTimeout)
+		defer cancel()
+		names, err := net.DefaultResolver.LookupAddr(ctx, addr)
+		if err != nil || len(names) == 0 {
+			return "", utils.PTRNegativeTTL
+		}
+		return strings.TrimSuffix(names[0], "."), utils.PTRDefaultTTL
+	}
+
+	resp, err := queryDNS(server, reverse, dns.TypePTR, false)
+	if err != nil {
+		return "", utils.PTRNegativeTTL
+	}
+	for _, rr := range resp.Answer {
+		if ptr, ok := rr.(*dns.PTR); ok {
+			ttl := time.Duration(ptr.Hdr.Ttl) * time.Second
+			if ttl < utils.PTRMinTTL {
+				ttl = utils.PTRMinTTL
+			}
+			return strings.TrimSuffix(ptr.Ptr, "."), ttl
+		}
+	}
+	return "", utils.PTRNegativeTTL
+}
+
+// hostFromAddr returns the IP part of an "IP:port" address; IPv6 addresses may be unbracketed
+func hostFromAddr(addr string) string {
+	if net.ParseIP(addr) != nil {
+		return addr
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	if idx := strings.LastIndex(addr, ":"); idx > 0 {
+		return addr[:idx]
+	}
+	return addr
+}
+
+// withName appends a resolved name to an address for display
+func withName(addr, name string) string {
+	if name == "" {
+		return addr
+	}
+	return addr + " (" + name + ")"
+}
@@ -0,0 +1,199 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+// ReflectorResponse is what the reflector tells a caller about itself
+type ReflectorResponse struct {
+	IP       string              `json:"ip"`
+	Port     int                 `json:"port"`
+	Family   string              `json:"family"`
+	Protocol string              `json:"protocol"` // http or udp
+	Method   string              `json:"method,omitempty"`
+	Path     string              `json:"path,omitempty"`
+	Headers  map[string][]string `json:"headers,omitempty"`
+	Time     time.Time           `json:"time"`
+}
+
+// ReflectorConfig controls the reflector server
+type ReflectorConfig struct {
+	HTTPAddr   string // e.g. ":8080"
+	UDPAddr    string // empty disables the UDP reflector
+	TrustProxy bool   // take the caller from X-Forwarded-For / X-Real-IP
+}
+
+// ServeReflector answers every request with the caller's address until interrupted.
+// HTTP: plain text by default, JSON on /json, ?format=json or Accept: application/json.
+// UDP: a datagram gets "ip:port", or JSON when it starts with "json" and is padded to
+// at least the size of the answer. No reply is ever larger than the datagram it answers,
+// so spoofed sources cannot use the reflector for amplification.
+func ServeReflector(cfg ReflectorConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		handleReflect(w, r, cfg.TrustProxy)
+	})
+	server := &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: utils.HTTPTimeout,
+		WriteTimeout:      utils.HTTPTimeout,
+		IdleTimeout:       4 * utils.HTTPTimeout,
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		display.PrintSuccess(fmt.Sprintf("HTTP reflector listening on %s", cfg.HTTPAddr))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errs <- utils.WrapError(err, "HTTP reflector failed", utils.ErrorTypeNetwork)
+		}
+	}()
+
+	if cfg.UDPAddr != "" {
+		conn, err := net.ListenPacket("udp", cfg.UDPAddr)
+		if err != nil {
+			return utils.WrapError(err, "Failed to open UDP reflector", utils.ErrorTypeNetwork)
+		}
+		defer conn.Close()
+		display.PrintSuccess(fmt.Sprintf("UDP reflector listening on %s", cfg.UDPAddr))
+		go func() {
+			errs <- serveUDPReflector(conn)
+		}()
+	}
+
+	display.PrintInfo("Press Ctrl-C to stop")
+
+	select {
+	case <-ctx.Done():
+	case err := <-errs:
+		server.Close()
+		return err
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), utils.HTTPTimeout)
+	defer cancel()
+	return server.Shutdown(shutdown)
+}
+
+// handleReflect answers one HTTP request
+func handleReflect(w http.ResponseWriter, r *http.Request, trustProxy bool) {
+	host, portText, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	port, _ := strconv.Atoi(portText)
+
+	// Behind a reverse proxy the socket peer is the proxy itself
+	if trustProxy {
+		if ip := forwardedIP(r); ip != nil {
+			host = ip.String()
+			port = 0
+		}
+	}
+
+	response := ReflectorResponse{
+		IP:       host,
+		Port:     port,
+		Family:   ipFamily(host),
+		Protocol: "http",
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Headers:  r.Header,
+		Time:     time.Now().UTC(),
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, response.IP)
+}
+
+// forwardedIP returns the client address set by a reverse proxy, or nil when the
+// headers are absent or do not hold an IP address.
+// Proxies append the peer they saw to X-Forwarded-For, so only the rightmost entry
+// comes from the trusted proxy; anything left of it is whatever the client sent.
+func forwardedIP(r *http.Request) net.IP {
+	if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+		entries := strings.Split(values[len(values)-1], ",")
+		return normalizeIP(net.ParseIP(strings.TrimSpace(entries[len(entries)-1])))
+	}
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return normalizeIP(net.ParseIP(strings.TrimSpace(realIP)))
+	}
+	return nil
+}
+
+// normalizeIP shows IPv4-mapped IPv6 addresses in dotted form
+func normalizeIP(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	return ip
+}
+
+// wantsJSON reports whether the caller asked for a JSON answer
+func wantsJSON(r *http.Request) bool {
+	return r.URL.Path == "/json" ||
+		r.URL.Query().Get("format") == "json" ||
+		strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// serveUDPReflector answers each datagram with the address it came from
+func serveUDPReflector(conn net.PacketConn) error {
+	buf := make([]byte, 512)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return utils.WrapError(err, "UDP reflector failed", utils.ErrorTypeNetwork)
+		}
+
+		addr, ok := from.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+		ip := normalizeIP(addr.IP).String()
+
+		reply := []byte(net.JoinHostPort(ip, strconv.Itoa(addr.Port)) + "\n")
+		if strings.HasPrefix(strings.ToLower(string(buf[:n])), "json") {
+			data, _ := json.Marshal(ReflectorResponse{
+				IP:       ip,
+				Port:     addr.Port,
+				Family:   ipFamily(ip),
+				Protocol: "udp",
+				Time:     time.Now().UTC(),
+			})
+			// Unpadded JSON requests fall back to the short answer
+			if len(data) <= n {
+				reply = data
+			}
+		}
+		// Never send more than was received: the source address may be spoofed
+		if len(reply) > n {
+			continue
+		}
+		conn.WriteTo(reply, from)
+	}
+}
@@ -0,0 +1,75 @@
+package network
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleReflectTrustProxy(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string][]string
+		trustProxy bool
+		want       string
+	}{
+		{
+			name: "no proxy headers",
+			want: "192.0.2.10",
+		},
+		{
+			name:    "headers ignored without --trust-proxy",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.7"}},
+			want:    "192.0.2.10",
+		},
+		{
+			// The client sent 203.0.113.66 itself; the proxy appended the real peer
+			name:       "spoofed X-Forwarded-For entry",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.66, 198.51.100.7"}},
+			trustProxy: true,
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "spoofed X-Forwarded-For header line",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.66", "198.51.100.7"}},
+			trustProxy: true,
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "X-Real-IP",
+			headers:    map[string][]string{"X-Real-Ip": {"2001:db8::7"}},
+			trustProxy: true,
+			want:       "2001:db8::7",
+		},
+		{
+			name:       "not an address",
+			headers:    map[string][]string{"X-Forwarded-For": {"<script>"}},
+			trustProxy: true,
+			want:       "192.0.2.10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/json", nil)
+			req.RemoteAddr = "192.0.2.10:40000"
+			for key, values := range tt.headers {
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+			recorder := httptest.NewRecorder()
+
+			handleReflect(recorder, req, tt.trustProxy)
+
+			var response ReflectorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if response.IP != tt.want {
+				t.Errorf("IP = %q, want %q", response.IP, tt.want)
+			}
+		})
+	}
+}
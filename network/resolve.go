@@ -0,0 +1,461 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"netinfo/display"
+	"netinfo/utils"
+
+	"github.com/miekg/dns"
+)
+
+// Name service lookup results
+const (
+	LookupFound    = "FOUND"
+	LookupNotFound = "NOTFOUND"
+	LookupUnavail  = "UNAVAIL"
+	LookupSkipped  = "SKIPPED"
+)
+
+// systemd-resolved stub listener used by the nss "resolve" module
+const resolvedStubAddress = "127.0.0.53"
+
+// HostsEntry holds a single line of the hosts file
+type HostsEntry struct {
+	IP    string   `json:"ip"`
+	Names []string `json:"names"`
+	Line  int      `json:"line"`
+}
+
+// NSSSource holds one source from the nsswitch hosts line with its status actions
+type NSSSource struct {
+	Name    string            `json:"name"`
+	Actions map[string]string `json:"actions"` // status -> return/continue
+}
+
+// ResolveStep holds the outcome of one name service source
+type ResolveStep struct {
+	Source  string   `json:"source"`
+	Queries []string `json:"queries"`
+	Result  string   `json:"result"`
+	Answers []string `json:"answers"`
+	Detail  string   `json:"detail"`
+}
+
+// ResolveExplanation holds the full resolution path for a name
+type ResolveExplanation struct {
+	Name         string        `json:"name"`
+	Sources      []NSSSource   `json:"sources"`
+	HostsFile    string        `json:"hosts_file"`
+	HostsMatches []HostsEntry  `json:"hosts_matches"`
+	SearchList   []string      `json:"search_list"`
+	NDots        int           `json:"ndots"`
+	Candidates   []string      `json:"candidates"`
+	Steps        []ResolveStep `json:"steps"`
+	Answers      []string      `json:"answers"`
+	AnsweredBy   string        `json:"answered_by"`
+}
+
+// ExplainResolution walks the host name lookup path the way the system resolver would
+func ExplainResolution(name string) (*ResolveExplanation, error) {
+	dnsConfig, err := getDNSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	hostsFile := hostsFilePath()
+	hostsEntries, err := parseHostsFile(hostsFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %v", hostsFile, err)
+	}
+
+	explanation := &ResolveExplanation{
+		Name:         name,
+		Sources:      parseNSSwitchHosts("/etc/nsswitch.conf"),
+		HostsFile:    hostsFile,
+		HostsMatches: matchHostsEntries(hostsEntries, name),
+		SearchList:   dnsConfig.SearchList,
+		NDots:        dnsConfig.NDots,
+		Candidates:   searchCandidates(name, dnsConfig.SearchList, dnsConfig.NDots),
+	}
+
+	servers := uniqueDNSServers(dnsConfig)
+
+	for _, source := range explanation.Sources {
+		step := ResolveStep{Source: source.Name}
+
+		switch source.Name {
+		case "files":
+			step.Queries = []string{name}
+			for _, entry := range explanation.HostsMatches {
+				step.Answers = append(step.Answers, entry.IP)
+			}
+			step.Detail = hostsFile
+			if len(step.Answers) > 0 {
+				step.Result = LookupFound
+				step.Detail = fmt.Sprintf("%s line %d", hostsFile, explanation.HostsMatches[0].Line)
+			} else {
+				step.Result = LookupNotFound
+			}
+
+		case "dns":
+			if len(servers) == 0 {
+				step.Result = LookupUnavail
+				step.Detail = utils.MsgNoDNSServers
+			} else {
+				resolveCandidates(&step, servers[0], explanation.Candidates)
+			}
+
+		case "resolve":
+			resolveCandidates(&step, resolvedStubAddress, explanation.Candidates)
+
+		case "myhostname":
+			step.Queries = []string{name}
+			step.Answers = myHostnameAnswers(name)
+			step.Result = LookupNotFound
+			if len(step.Answers) > 0 {
+				step.Result = LookupFound
+			}
+
+		case "mdns", "mdns4", "mdns6", "mdns_minimal", "mdns4_minimal", "mdns6_minimal":
+			if !strings.HasSuffix(strings.TrimSuffix(strings.ToLower(name), "."), ".local") {
+				// nss-mdns returns UNAVAIL, not NOTFOUND, so [NOTFOUND=return] falls through to dns
+				step.Result = LookupUnavail
+				step.Detail = "only answers .local names"
+			} else {
+				step.Result = LookupSkipped
+				step.Detail = "multicast DNS is not queried"
+			}
+
+		default:
+			step.Result = LookupSkipped
+			step.Detail = "source not evaluated"
+		}
+
+		explanation.Steps = append(explanation.Steps, step)
+
+		if step.Result == LookupFound {
+			explanation.Answers = step.Answers
+			explanation.AnsweredBy = source.Name
+		}
+		if nssAction(source, step.Result) == "return" {
+			break
+		}
+	}
+
+	return explanation, nil
+}
+
+// resolveCandidates queries each candidate FQDN in order until one has addresses
+func resolveCandidates(step *ResolveStep, server string, candidates []string) {
+	step.Result = LookupNotFound
+	for _, candidate := range candidates {
+		step.Queries = append(step.Queries, candidate)
+
+		answer := lookupAddresses(server, candidate)
+		if len(answer.Addresses) > 0 {
+			step.Result = LookupFound
+			step.Answers = answer.Addresses
+			step.Detail = fmt.Sprintf("%s answered by %s", candidate, server)
+			return
+		}
+		if answer.Error != "" {
+			step.Result = LookupUnavail
+			step.Detail = answer.Error
+			return
+		}
+	}
+	step.Detail = fmt.Sprintf("no candidate resolved via %s", server)
+}
+
+// searchCandidates lists the FQDNs the resolver tries, in order, for name given the search list and ndots
+func searchCandidates(name string, searchList []string, ndots int) []string {
+	if strings.HasSuffix(name, ".") {
+		return []string{name}
+	}
+
+	var searched []string
+	for _, domain := range searchList {
+		searched = append(searched, dns.Fqdn(name+"."+strings.TrimSuffix(domain, ".")))
+	}
+
+	absolute := dns.Fqdn(name)
+	if strings.Count(name, ".") >= ndots {
+		return append([]string{absolute}, searched...)
+	}
+	return append(searched, absolute)
+}
+
+// parseNSSwitchHosts reads the hosts line from nsswitch.conf, defaulting to "files dns"
+func parseNSSwitchHosts(path string) []NSSSource {
+	defaultSources := []NSSSource{{Name: "files"}, {Name: "dns"}}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return defaultSources
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "hosts:") {
+			continue
+		}
+
+		var sources []NSSSource
+		for _, field := range strings.Fields(strings.TrimPrefix(line, "hosts:")) {
+			if strings.HasPrefix(field, "#") {
+				break
+			}
+			// Action blocks like [NOTFOUND=return] may be split across fields
+			if strings.HasPrefix(field, "[") || (len(sources) > 0 && strings.HasSuffix(field, "]")) {
+				if len(sources) == 0 {
+					continue
+				}
+				last := &sources[len(sources)-1]
+				if last.Actions == nil {
+					last.Actions = make(map[string]string)
+				}
+				for _, action := range strings.Fields(strings.Trim(field, "[]")) {
+					status, value, ok := strings.Cut(action, "=")
+					if !ok {
+						continue
+					}
+					status = strings.ToUpper(status)
+					if negated, ok := strings.CutPrefix(status, "!"); ok {
+						// [!STATUS=action] applies to every other status
+						for _, other := range []string{"SUCCESS", LookupNotFound, LookupUnavail, "TRYAGAIN"} {
+							if other != negated {
+								last.Actions[other] = strings.ToLower(value)
+							}
+						}
+						continue
+					}
+					last.Actions[status] = strings.ToLower(value)
+				}
+				continue
+			}
+			sources = append(sources, NSSSource{Name: field})
+		}
+
+		if len(sources) > 0 {
+			return sources
+		}
+	}
+
+	return defaultSources
+}
+
+// nssAction returns the action nsswitch takes after a source returned result
+func nssAction(source NSSSource, result string) string {
+	status := result
+	if result == LookupFound {
+		status = "SUCCESS"
+	}
+	if action, ok := source.Actions[status]; ok {
+		return action
+	}
+	if status == "SUCCESS" {
+		return "return"
+	}
+	return "continue"
+}
+
+// hostsFilePath returns the location of the hosts file for the current platform
+func hostsFilePath() string {
+	if utils.IsWindows() {
+		systemRoot := os.Getenv("SystemRoot")
+		if systemRoot == "" {
+			systemRoot = `C:\Windows`
+		}
+		return filepath.Join(systemRoot, "System32", "drivers", "etc", "hosts")
+	}
+	return "/etc/hosts"
+}
+
+// parseHostsFile reads all entries from a hosts file
+func parseHostsFile(path string) ([]HostsEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []HostsEntry
+	for i, line := range strings.Split(string(content), "\n") {
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		entries = append(entries, HostsEntry{
+			IP:    fields[0],
+			Names: fields[1:],
+			Line:  i + 1,
+		})
+	}
+
+	return entries, nil
+}
+
+// matchHostsEntries returns the hosts entries that list name as a hostname or alias
+func matchHostsEntries(entries []HostsEntry, name string) []HostsEntry {
+	name = strings.TrimSuffix(name, ".")
+
+	var matches []HostsEntry
+	for _, entry := range entries {
+		for _, entryName := range entry.Names {
+			if strings.EqualFold(entryName, name) {
+				matches = append(matches, entry)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// myHostnameAnswers mimics nss-myhostname: the local hostname, localhost and _gateway
+func myHostnameAnswers(name string) []string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+
+	if name == "localhost" || strings.HasSuffix(name, ".localhost") {
+		return []string{"127.0.0.1", "::1"}
+	}
+
+	if name == "_gateway" {
+		var answers []string
+		for _, version := range []string{"IPv4", "IPv6"} {
+			if gateway, err := GetDefaultGateway(version); err == nil {
+				answers = append(answers, gateway.Gateway)
+			}
+		}
+		return answers
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || !strings.EqualFold(hostname, name) {
+		return nil
+	}
+
+	var answers []string
+	localIPs, err := GetLocalIPs()
+	if err == nil {
+		for _, ipInfo := range localIPs {
+			answers = append(answers, ipInfo.LocalIPs...)
+		}
+	}
+	if len(answers) == 0 {
+		answers = []string{"127.0.0.2", "::1"}
+	}
+	return answers
+}
+
+// ShowResolveExplain displays the resolution path for name
+func ShowResolveExplain(name string) error {
+	display.PrintInfo(fmt.Sprintf("Explaining name resolution for: %s", name))
+
+	explanation, err := ExplainResolution(name)
+	if err != nil {
+		display.PrintError(fmt.Sprintf("Failed to explain resolution: %v", err))
+		return err
+	}
+
+	// Lookup order
+	var order []string
+	for _, source := range explanation.Sources {
+		entry := source.Name
+		var statuses []string
+		for status := range source.Actions {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			entry += fmt.Sprintf(" [%s=%s]", status, source.Actions[status])
+		}
+		order = append(order, entry)
+	}
+	display.PrintList(order, "nsswitch hosts order")
+
+	// Hosts file matches
+	if len(explanation.HostsMatches) > 0 {
+		var tableData [][]string
+		for _, entry := range explanation.HostsMatches {
+			row := []string{
+				fmt.Sprintf("%d", entry.Line),
+				entry.IP,
+				strings.Join(entry.Names, " "),
+			}
+			tableData = append(tableData, row)
+		}
+
+		tableConfig := display.NewTableConfig()
+		tableConfig.Title = fmt.Sprintf("Matching entries in %s", explanation.HostsFile)
+		tableConfig.Headers = []string{"Line", "Address", "Names"}
+		tableConfig.Data = tableData
+
+		display.PrintTable(tableConfig)
+	} else {
+		display.PrintInfo(fmt.Sprintf("No entries for %s in %s", name, explanation.HostsFile))
+	}
+
+	// Search list expansion
+	searchList := strings.Join(explanation.SearchList, " ")
+	if searchList == "" {
+		searchList = "none"
+	}
+	display.PrintList(explanation.Candidates,
+		fmt.Sprintf("DNS candidates (ndots:%d, %d dots in name, search: %s)",
+			explanation.NDots,
+			strings.Count(strings.TrimSuffix(name, "."), "."),
+			searchList))
+
+	// Lookup walk
+	var tableData [][]string
+	for i, step := range explanation.Steps {
+		result := step.Result
+		switch result {
+		case LookupFound:
+			result = display.Success(result)
+		case LookupUnavail:
+			result = display.Error(result)
+		default:
+			result = display.Warning(result)
+		}
+
+		answers := strings.Join(step.Answers, ", ")
+		if answers == "" {
+			answers = "-"
+		}
+
+		row := []string{
+			fmt.Sprintf("%d", i+1),
+			step.Source,
+			strings.Join(step.Queries, ", "),
+			result,
+			answers,
+			step.Detail,
+		}
+		tableData = append(tableData, row)
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Resolution Path"
+	tableConfig.Headers = []string{"Step", "Source", "Queried", "Result", "Answers", "Detail"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+
+	display.PrintTable(tableConfig)
+
+	if explanation.AnsweredBy != "" {
+		display.PrintSuccess(fmt.Sprintf("%s resolved to %s by the %s source",
+			name, display.IP(strings.Join(explanation.Answers, ", ")), explanation.AnsweredBy))
+	} else {
+		display.PrintWarning(fmt.Sprintf("%s did not resolve through any source", name))
+	}
+
+	return nil
+}
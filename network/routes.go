@@ -17,6 +17,7 @@ import (
 type RouteInfo struct {
 	Destination string `json:"destination"`
 	Gateway     string `json:"gateway"`
+	GatewayName string `json:"gateway_name,omitempty"` // PTR name of the gateway
 	Interface   string `json:"interface"`
 	Metric      int    `json:"metric"`
 	Protocol    string `json:"protocol"`
@@ -59,6 +60,18 @@ func ShowRoutingTable() error {
 	
 	display.PrintSuccess(fmt.Sprintf("Found %d routing table entries", len(routeConfig.Routes)))
 	
+	// Add PTR names for gateways
+	if ResolveNamesEnabled() {
+		var gateways []string
+		for _, route := range routeConfig.Routes {
+			gateways = append(gateways, route.Gateway)
+		}
+		names := LookupPTRs(gateways, utils.PTRLookupDeadline)
+		for i := range routeConfig.Routes {
+			routeConfig.Routes[i].GatewayName = names[routeConfig.Routes[i].Gateway]
+		}
+	}
+	
 	// Sort routes by destination for better readability
 	sort.Slice(routeConfig.Routes, func(i, j int) bool {
 		return routeConfig.Routes[i].Destination < routeConfig.Routes[j].Destination
@@ -68,7 +81,7 @@ func ShowRoutingTable() error {
 	var tableData [][]string
 	for _, route := range routeConfig.Routes {
 		// Format gateway
-		gateway := route.Gateway
+		gateway := withName(route.Gateway, route.GatewayName)
 		if gateway == "" {
 			gateway = "On-link"
 		}
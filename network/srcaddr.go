@@ -0,0 +1,473 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+// Address scopes (RFC 6724 section 3.1, RFC 4291 multicast scope values)
+const (
+	scopeInterfaceLocal = 0x1
+	scopeLinkLocal      = 0x2
+	scopeSiteLocal      = 0x5
+	scopeGlobal         = 0xe
+)
+
+// RFC 6724 source address selection rules
+const (
+	RuleSameAddress    = "Rule 1: prefer same address"
+	RuleScope          = "Rule 2: prefer appropriate scope"
+	RuleDeprecated     = "Rule 3: avoid deprecated addresses"
+	RuleHome           = "Rule 4: prefer home addresses"
+	RuleInterface      = "Rule 5: prefer outgoing interface"
+	RuleLabel          = "Rule 6: prefer matching label"
+	RuleTemporary      = "Rule 7: prefer temporary addresses"
+	RuleLongestPrefix  = "Rule 8: use longest matching prefix"
+	RuleImplementation = "Tie: implementation order"
+)
+
+// policyEntry is a row of the RFC 6724 default policy table
+type policyEntry struct {
+	prefix     netip.Prefix
+	precedence int
+	label      int
+}
+
+// defaultPolicyTable is the RFC 6724 section 2.1 policy table, longest prefixes first
+var defaultPolicyTable = []policyEntry{
+	{netip.MustParsePrefix("::1/128"), 50, 0},
+	{netip.MustParsePrefix("::ffff:0:0/96"), 35, 4},
+	{netip.MustParsePrefix("::/96"), 1, 3},
+	{netip.MustParsePrefix("2001::/32"), 5, 5},
+	{netip.MustParsePrefix("2002::/16"), 30, 2},
+	{netip.MustParsePrefix("3ffe::/16"), 1, 12},
+	{netip.MustParsePrefix("fec0::/10"), 1, 11},
+	{netip.MustParsePrefix("fc00::/7"), 3, 13},
+	{netip.MustParsePrefix("::/0"), 40, 1},
+}
+
+// SourceCandidate is a local address considered as source for a destination
+type SourceCandidate struct {
+	Address    string `json:"address"`
+	Interface  string `json:"interface"`
+	PrefixLen  int    `json:"prefix_len"`
+	Scope      int    `json:"scope"`
+	Label      int    `json:"label"`
+	Precedence int    `json:"precedence"`
+	Deprecated bool   `json:"deprecated"`
+	Temporary  bool   `json:"temporary"`
+	Home       bool   `json:"home"`                 // Mobile IPv6 home address
+	CommonLen  int    `json:"common_prefix_len"`    // with the destination, capped at PrefixLen
+	DecidedBy  string `json:"decided_by,omitempty"` // rule that ranked it above the next candidate
+}
+
+// SourceSelection explains which source address is used for a destination
+type SourceSelection struct {
+	Destination      string            `json:"destination"`
+	DestinationScope int               `json:"destination_scope"`
+	DestinationLabel int               `json:"destination_label"`
+	OutInterface     string            `json:"out_interface"`
+	Candidates       []SourceCandidate `json:"candidates"` // best first
+	Selected         string            `json:"selected"`
+	KernelChoice     string            `json:"kernel_choice"`
+	Match            bool              `json:"match"`
+}
+
+// ExplainSourceSelection ranks the local addresses for dest using the RFC 6724 rules
+func ExplainSourceSelection(dest string) (*SourceSelection, error) {
+	destAddr, err := netip.ParseAddr(dest)
+	if err != nil {
+		return nil, utils.WrapError(err, fmt.Sprintf("invalid destination address: %s", dest), utils.ErrorTypeValidation)
+	}
+	destAddr = destAddr.Unmap().WithZone("")
+
+	result := &SourceSelection{
+		Destination:      destAddr.String(),
+		DestinationScope: addressScope(destAddr),
+		DestinationLabel: policyFor(destAddr).label,
+	}
+
+	result.OutInterface, result.KernelChoice = kernelSourceAddress(destAddr)
+
+	candidates, err := sourceCandidates(destAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		better, _ := compareSources(candidates[i], candidates[j], destAddr, result)
+		return better < 0
+	})
+	for i := 0; i+1 < len(candidates); i++ {
+		_, candidates[i].DecidedBy = compareSources(candidates[i], candidates[i+1], destAddr, result)
+	}
+
+	result.Candidates = candidates
+	if len(candidates) > 0 {
+		result.Selected = candidates[0].Address
+	}
+	result.Match = result.Selected != "" && result.Selected == result.KernelChoice
+
+	return result, nil
+}
+
+// sourceCandidates lists the local addresses of the destination's family
+func sourceCandidates(dest netip.Addr) ([]SourceCandidate, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, utils.WrapError(err, utils.ErrNetworkInterfaces, utils.ErrorTypeNetwork)
+	}
+	flags := ipv6AddressFlags()
+
+	var candidates []SourceCandidate
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			prefix, err := netip.ParsePrefix(addr.String())
+			if err != nil {
+				continue
+			}
+			source := prefix.Addr().Unmap()
+			if source.Is4() != dest.Is4() {
+				continue
+			}
+			// Loopback sources are only usable for loopback destinations
+			if source.IsLoopback() && !dest.IsLoopback() {
+				continue
+			}
+
+			policy := policyFor(source)
+			candidate := SourceCandidate{
+				Address:    source.String(),
+				Interface:  iface.Name,
+				PrefixLen:  prefix.Bits(),
+				Scope:      addressScope(source),
+				Label:      policy.label,
+				Precedence: policy.precedence,
+				CommonLen:  commonPrefixLen(source, dest, prefix.Bits()),
+			}
+			if source.Is6() {
+				addrFlags := flags[source.String()]
+				// Addresses still in or failed duplicate address detection are not usable (section 4)
+				if addrFlags&(ifaFlagTentative|ifaFlagDADFailed) != 0 {
+					continue
+				}
+				candidate.Home = addrFlags&ifaFlagHomeAddress != 0
+				candidate.Deprecated = addrFlags&ifaFlagDeprecated != 0
+				candidate.Temporary = addrFlags&ifaFlagTemporary != 0
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
+// compareSources applies the RFC 6724 section 5 rules in order.
+// It returns -1 when a is preferred, 1 when b is preferred, and the deciding rule.
+func compareSources(a, b SourceCandidate, dest netip.Addr, selection *SourceSelection) (int, string) {
+	destination := dest.String()
+
+	// Rule 1
+	if a.Address == destination {
+		return -1, RuleSameAddress
+	}
+	if b.Address == destination {
+		return 1, RuleSameAddress
+	}
+
+	// Rule 2
+	destScope := selection.DestinationScope
+	if a.Scope < b.Scope {
+		if a.Scope < destScope {
+			return 1, RuleScope
+		}
+		return -1, RuleScope
+	}
+	if b.Scope < a.Scope {
+		if b.Scope < destScope {
+			return -1, RuleScope
+		}
+		return 1, RuleScope
+	}
+
+	// Rule 3
+	if a.Deprecated != b.Deprecated {
+		if b.Deprecated {
+			return -1, RuleDeprecated
+		}
+		return 1, RuleDeprecated
+	}
+
+	// Rule 4; care-of addresses are not flagged, so any home address beats a non-home one
+	if a.Home != b.Home {
+		if a.Home {
+			return -1, RuleHome
+		}
+		return 1, RuleHome
+	}
+
+	// Rule 5
+	if selection.OutInterface != "" && a.Interface != b.Interface {
+		if a.Interface == selection.OutInterface {
+			return -1, RuleInterface
+		}
+		if b.Interface == selection.OutInterface {
+			return 1, RuleInterface
+		}
+	}
+
+	// Rule 6
+	destLabel := selection.DestinationLabel
+	if (a.Label == destLabel) != (b.Label == destLabel) {
+		if a.Label == destLabel {
+			return -1, RuleLabel
+		}
+		return 1, RuleLabel
+	}
+
+	// Rule 7
+	if a.Temporary != b.Temporary {
+		if a.Temporary {
+			return -1, RuleTemporary
+		}
+		return 1, RuleTemporary
+	}
+
+	// Rule 8
+	if a.CommonLen != b.CommonLen {
+		if a.CommonLen > b.CommonLen {
+			return -1, RuleLongestPrefix
+		}
+		return 1, RuleLongestPrefix
+	}
+
+	return 0, RuleImplementation
+}
+
+// addressScope returns the RFC 6724 scope of an address
+func addressScope(addr netip.Addr) int {
+	if addr.Is4() {
+		// IPv4 loopback and auto-configured addresses are link-local, everything else global (section 3.2)
+		if addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+			return scopeLinkLocal
+		}
+		return scopeGlobal
+	}
+	switch {
+	case addr.IsMulticast():
+		return int(addr.As16()[1] & 0x0f)
+	case addr.IsLoopback(), addr.IsLinkLocalUnicast():
+		return scopeLinkLocal
+	case netip.MustParsePrefix("fec0::/10").Contains(addr):
+		return scopeSiteLocal
+	}
+	return scopeGlobal
+}
+
+// policyFor looks up an address in the default policy table; IPv4 is matched as ::ffff:a.b.c.d
+func policyFor(addr netip.Addr) policyEntry {
+	if addr.Is4() {
+		addr = netip.AddrFrom16(addr.As16())
+	}
+	for _, entry := range defaultPolicyTable {
+		if entry.prefix.Contains(addr) {
+			return entry
+		}
+	}
+	return defaultPolicyTable[len(defaultPolicyTable)-1]
+}
+
+// commonPrefixLen counts the leading bits shared by a and b, up to maxBits
+func commonPrefixLen(a, b netip.Addr, maxBits int) int {
+	aBytes, bBytes := a.AsSlice(), b.AsSlice()
+	length := 0
+	for i := range aBytes {
+		diff := aBytes[i] ^ bBytes[i]
+		if diff == 0 {
+			length += 8
+			continue
+		}
+		for mask := byte(0x80); mask != 0 && diff&mask == 0; mask >>= 1 {
+			length++
+		}
+		break
+	}
+	if length > maxBits {
+		length = maxBits
+	}
+	return length
+}
+
+// kernelSourceAddress asks the operating system which interface and source it would use
+func kernelSourceAddress(dest netip.Addr) (string, string) {
+	if utils.IsLinux() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		output, err := utils.CommandWithTimeout(ctx, 5*time.Second, "ip", "-j", "route", "get", dest.String())
+		if err == nil {
+			var routes []struct {
+				Dev     string `json:"dev"`
+				PrefSrc string `json:"prefsrc"`
+			}
+			if json.Unmarshal(output, &routes) == nil && len(routes) > 0 && routes[0].PrefSrc != "" {
+				return routes[0].Dev, routes[0].PrefSrc
+			}
+		}
+	}
+
+	// Connecting a UDP socket selects a source without sending anything
+	conn, err := net.Dial("udp", net.JoinHostPort(dest.String(), "9"))
+	if err != nil {
+		return "", ""
+	}
+	defer conn.Close()
+
+	local := conn.LocalAddr().(*net.UDPAddr)
+	return interfaceForAddress(local.IP), local.IP.String()
+}
+
+// interfaceForAddress returns the name of the interface holding ip
+func interfaceForAddress(ip net.IP) string {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+				return iface.Name
+			}
+		}
+	}
+	return ""
+}
+
+// scopeName returns a readable scope name
+func scopeName(scope int) string {
+	switch scope {
+	case scopeInterfaceLocal:
+		return "interface-local"
+	case scopeLinkLocal:
+		return "link-local"
+	case scopeSiteLocal:
+		return "site-local"
+	case scopeGlobal:
+		return "global"
+	}
+	return fmt.Sprintf("0x%x", scope)
+}
+
+// ShowSourceSelection explains the source address choice for a destination name or address
+func ShowSourceSelection(target string) error {
+	destinations := []string{target}
+	if net.ParseIP(target) == nil {
+		addrs, err := net.LookupHost(target)
+		if err != nil {
+			display.PrintError(fmt.Sprintf("Failed to resolve %s: %v", target, err))
+			return err
+		}
+		destinations = addrs
+	}
+
+	for _, dest := range destinations {
+		if err := showSourceSelection(dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// showSourceSelection displays the ranking for a single destination address
+func showSourceSelection(dest string) error {
+	selection, err := ExplainSourceSelection(dest)
+	if err != nil {
+		display.PrintError(err.Error())
+		return err
+	}
+
+	display.PrintInfo(fmt.Sprintf("Destination %s (scope %s, label %d), outgoing interface %s",
+		selection.Destination, scopeName(selection.DestinationScope), selection.DestinationLabel, valueOrDash(selection.OutInterface)))
+
+	if len(selection.Candidates) == 0 {
+		display.PrintWarning("No candidate source addresses of this family")
+		return nil
+	}
+
+	var tableData [][]string
+	for i, candidate := range selection.Candidates {
+		var flags []string
+		if candidate.Deprecated {
+			flags = append(flags, "deprecated")
+		}
+		if candidate.Temporary {
+			flags = append(flags, "temporary")
+		}
+		if candidate.Home {
+			flags = append(flags, "home")
+		}
+
+		address := candidate.Address
+		if i == 0 {
+			address = display.Success(address)
+		}
+
+		row := []string{
+			fmt.Sprintf("%d", i+1),
+			address,
+			candidate.Interface,
+			scopeName(candidate.Scope),
+			fmt.Sprintf("%d/%d", candidate.Label, candidate.Precedence),
+			valueOrDash(strings.Join(flags, ",")),
+			fmt.Sprintf("%d", candidate.CommonLen),
+			valueOrDash(candidate.DecidedBy),
+		}
+		tableData = append(tableData, row)
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = fmt.Sprintf("Source Address Selection for %s (RFC 6724)", selection.Destination)
+	tableConfig.Headers = []string{"#", "Address", "Interface", "Scope", "Label/Prec", "Flags", "Common Prefix", "Preferred Over Next By"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+
+	display.PrintTable(tableConfig)
+
+	switch {
+	case selection.KernelChoice == "":
+		display.PrintWarning(fmt.Sprintf("RFC 6724 selects %s; could not ask the kernel for its choice", selection.Selected))
+	case selection.Match:
+		display.PrintSuccess(fmt.Sprintf("Kernel also uses %s", selection.KernelChoice))
+	default:
+		display.PrintWarning(fmt.Sprintf("Kernel uses %s instead of %s (route src hint, policy routing or a different address policy table)",
+			selection.KernelChoice, selection.Selected))
+	}
+
+	return nil
+}
+
+// valueOrDash returns "-" for empty strings
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,254 @@
+package network
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+
+	"netinfo/utils"
+)
+
+// STUN message types and attributes (RFC 5389, RFC 5780)
+const (
+	stunBindingRequest  = 0x0001
+	stunBindingResponse = 0x0101
+	stunBindingError    = 0x0111
+	stunMagicCookie     = 0x2112A442
+	stunHeaderSize      = 20
+
+	stunAttrMappedAddress    = 0x0001
+	stunAttrChangeRequest    = 0x0003
+	stunAttrXORMappedAddress = 0x0020
+	stunAttrResponseOrigin   = 0x802b
+	stunAttrOtherAddress     = 0x802c
+
+	stunChangeIP   = 0x04
+	stunChangePort = 0x02
+)
+
+// STUNResponse holds the parsed attributes of a binding response
+type STUNResponse struct {
+	Mapped         *net.UDPAddr // reflexive transport address as seen by the server
+	ResponseOrigin *net.UDPAddr // address the response was sent from
+	OtherAddress   *net.UDPAddr // alternate address for RFC 5780 behaviour tests
+	From           *net.UDPAddr // address the response actually arrived from
+}
+
+// STUNBinding sends a binding request from conn to server and waits for the matching response.
+// changeIP and changePort ask the server to answer from its alternate address and/or port.
+func STUNBinding(conn *net.UDPConn, server *net.UDPAddr, changeIP, changePort bool, timeout time.Duration) (*STUNResponse, error) {
+	transactionID := make([]byte, 12)
+	if _, err := rand.Read(transactionID); err != nil {
+		return nil, err
+	}
+
+	request := buildSTUNRequest(transactionID, changeIP, changePort)
+
+	// Retransmit with doubling intervals until the overall timeout (RFC 5389 section 7.2.1)
+	deadline := time.Now().Add(timeout)
+	interval := 250 * time.Millisecond
+	buf := make([]byte, 1500)
+	for time.Now().Before(deadline) {
+		if _, err := conn.WriteToUDP(request, server); err != nil {
+			return nil, utils.WrapError(err, "STUN request failed", utils.ErrorTypeNetwork)
+		}
+
+		wait := time.Now().Add(interval)
+		if wait.After(deadline) {
+			wait = deadline
+		}
+		conn.SetReadDeadline(wait)
+
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				break
+			}
+			response, err := parseSTUNResponse(buf[:n], transactionID)
+			if err != nil {
+				continue
+			}
+			response.From = from
+			return response, nil
+		}
+		interval *= 2
+	}
+
+	return nil, utils.WrapError(nil, fmt.Sprintf("STUN request to %s timed out", server), utils.ErrorTypeTimeout)
+}
+
+// buildSTUNRequest encodes a binding request with an optional CHANGE-REQUEST attribute
+func buildSTUNRequest(transactionID []byte, changeIP, changePort bool) []byte {
+	var attrs []byte
+	if changeIP || changePort {
+		var flags uint32
+		if changeIP {
+			flags |= stunChangeIP
+		}
+		if changePort {
+			flags |= stunChangePort
+		}
+		attrs = make([]byte, 8)
+		binary.BigEndian.PutUint16(attrs[0:], stunAttrChangeRequest)
+		binary.BigEndian.PutUint16(attrs[2:], 4)
+		binary.BigEndian.PutUint32(attrs[4:], flags)
+	}
+
+	msg := make([]byte, stunHeaderSize, stunHeaderSize+len(attrs))
+	binary.BigEndian.PutUint16(msg[0:], stunBindingRequest)
+	binary.BigEndian.PutUint16(msg[2:], uint16(len(attrs)))
+	binary.BigEndian.PutUint32(msg[4:], stunMagicCookie)
+	copy(msg[8:], transactionID)
+	return append(msg, attrs...)
+}
+
+// parseSTUNResponse decodes a binding response matching transactionID
+func parseSTUNResponse(msg, transactionID []byte) (*STUNResponse, error) {
+	if len(msg) < stunHeaderSize {
+		return nil, fmt.Errorf("short STUN message")
+	}
+	msgType := binary.BigEndian.Uint16(msg[0:])
+	length := int(binary.BigEndian.Uint16(msg[2:]))
+	if binary.BigEndian.Uint32(msg[4:]) != stunMagicCookie || string(msg[8:20]) != string(transactionID) {
+		return nil, fmt.Errorf("unexpected STUN transaction")
+	}
+	if msgType == stunBindingError {
+		return nil, fmt.Errorf("STUN error response")
+	}
+	if msgType != stunBindingResponse || stunHeaderSize+length > len(msg) {
+		return nil, fmt.Errorf("malformed STUN response")
+	}
+
+	response := &STUNResponse{}
+	attrs := msg[stunHeaderSize : stunHeaderSize+length]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:]))
+		if 4+attrLen > len(attrs) {
+			break
+		}
+		value := attrs[4 : 4+attrLen]
+
+		switch attrType {
+		case stunAttrXORMappedAddress:
+			response.Mapped = decodeSTUNAddress(value, msg[4:20])
+		case stunAttrMappedAddress:
+			if response.Mapped == nil {
+				response.Mapped = decodeSTUNAddress(value, nil)
+			}
+		case stunAttrResponseOrigin:
+			response.ResponseOrigin = decodeSTUNAddress(value, nil)
+		case stunAttrOtherAddress:
+			response.OtherAddress = decodeSTUNAddress(value, nil)
+		}
+
+		// Attributes are padded to a multiple of four bytes
+		padded := (attrLen + 3) &^ 3
+		if 4+padded > len(attrs) {
+			break
+		}
+		attrs = attrs[4+padded:]
+	}
+
+	if response.Mapped == nil {
+		return nil, fmt.Errorf("STUN response has no mapped address")
+	}
+	return response, nil
+}
+
+// decodeSTUNAddress decodes a (XOR-)MAPPED-ADDRESS style attribute; xorKey is the
+// magic cookie followed by the transaction ID, or nil for plain addresses
+func decodeSTUNAddress(value, xorKey []byte) *net.UDPAddr {
+	if len(value) < 4 {
+		return nil
+	}
+
+	family := value[1]
+	port := binary.BigEndian.Uint16(value[2:])
+	var ip net.IP
+	switch family {
+	case 0x01:
+		if len(value) < 8 {
+			return nil
+		}
+		ip = net.IP(append([]byte{}, value[4:8]...))
+	case 0x02:
+		if len(value) < 20 {
+			return nil
+		}
+		ip = net.IP(append([]byte{}, value[4:20]...))
+	default:
+		return nil
+	}
+
+	if xorKey != nil {
+		port ^= uint16(stunMagicCookie >> 16)
+		for i := range ip {
+			ip[i] ^= xorKey[i]
+		}
+	}
+
+	return &net.UDPAddr{IP: ip, Port: int(port)}
+}
+
+// udpNetwork returns the UDP network name for an address family
+func udpNetwork(family string) string {
+	if family == "IPv6" {
+		return "udp6"
+	}
+	return "udp4"
+}
+
+// stunPublicIP discovers the public address of one family through a STUN server
+func stunPublicIP(ctx context.Context, server, family string) (string, error) {
+	network := udpNetwork(family)
+	serverAddr, err := resolveUDPAddr(ctx, network, server)
+	if err != nil {
+		return "", utils.WrapError(err, "Failed to resolve STUN server", utils.ErrorTypeNetwork)
+	}
+
+	conn, err := net.ListenUDP(network, nil)
+	if err != nil {
+		return "", utils.WrapError(err, "Failed to open UDP socket", utils.ErrorTypeNetwork)
+	}
+	defer conn.Close()
+
+	// Closing the socket ends the retransmissions as soon as ctx is done
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	response, err := STUNBinding(conn, serverAddr, false, false, utils.STUNTimeout)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", utils.WrapError(ctx.Err(), "STUN request cancelled", utils.ErrorTypeTimeout)
+		}
+		return "", err
+	}
+	return response.Mapped.IP.String(), nil
+}
+
+// resolveUDPAddr is net.ResolveUDPAddr that gives up when ctx is done
+func resolveUDPAddr(ctx context.Context, network, address string) (*net.UDPAddr, error) {
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := net.DefaultResolver.LookupPort(ctx, network, portText)
+	if err != nil {
+		return nil, err
+	}
+
+	ipNetwork := "ip4"
+	if network == "udp6" {
+		ipNetwork = "ip6"
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, ipNetwork, host)
+	if err != nil {
+		return nil, err
+	}
+	return net.UDPAddrFromAddrPort(netip.AddrPortFrom(ips[0].Unmap(), uint16(port))), nil
+}
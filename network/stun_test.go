@@ -0,0 +1,191 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// stunTestResponder answers binding requests on a local UDP socket with reply
+func stunTestResponder(t *testing.T, reply func(request []byte, from *net.UDPAddr) []byte) *net.UDPAddr {
+	t.Helper()
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			if response := reply(buf[:n], from); response != nil {
+				conn.WriteToUDP(response, from)
+			}
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr)
+}
+
+// buildSTUNTestResponse encodes a binding response carrying addr in attrType
+func buildSTUNTestResponse(transactionID []byte, attrType uint16, addr *net.UDPAddr) []byte {
+	ip := addr.IP.To4()
+	value := make([]byte, 8)
+	value[1] = 0x01
+	binary.BigEndian.PutUint16(value[2:], uint16(addr.Port))
+	copy(value[4:], ip)
+	if attrType == stunAttrXORMappedAddress {
+		binary.BigEndian.PutUint16(value[2:], uint16(addr.Port)^uint16(stunMagicCookie>>16))
+		binary.BigEndian.PutUint32(value[4:], binary.BigEndian.Uint32(ip)^stunMagicCookie)
+	}
+
+	msg := make([]byte, stunHeaderSize+4+len(value))
+	binary.BigEndian.PutUint16(msg[0:], stunBindingResponse)
+	binary.BigEndian.PutUint16(msg[2:], uint16(4+len(value)))
+	binary.BigEndian.PutUint32(msg[4:], stunMagicCookie)
+	copy(msg[8:20], transactionID)
+	binary.BigEndian.PutUint16(msg[20:], attrType)
+	binary.BigEndian.PutUint16(msg[22:], uint16(len(value)))
+	copy(msg[24:], value)
+	return msg
+}
+
+func TestSTUNBinding(t *testing.T) {
+	mapped := &net.UDPAddr{IP: net.IPv4(203, 0, 113, 7), Port: 40000}
+
+	tests := []struct {
+		name    string
+		reply   func(request []byte) []byte
+		wantErr bool
+	}{
+		{
+			name: "xor-mapped-address",
+			reply: func(request []byte) []byte {
+				return buildSTUNTestResponse(request[8:20], stunAttrXORMappedAddress, mapped)
+			},
+		},
+		{
+			name: "mapped-address",
+			reply: func(request []byte) []byte {
+				return buildSTUNTestResponse(request[8:20], stunAttrMappedAddress, mapped)
+			},
+		},
+		{
+			name: "transaction-id-mismatch",
+			reply: func(request []byte) []byte {
+				other := bytes.Repeat([]byte{0xee}, 12)
+				return buildSTUNTestResponse(other, stunAttrXORMappedAddress, mapped)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := stunTestResponder(t, func(request []byte, _ *net.UDPAddr) []byte {
+				return tt.reply(request)
+			})
+			conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			if err != nil {
+				t.Fatalf("listen: %v", err)
+			}
+			defer conn.Close()
+
+			response, err := STUNBinding(conn, server, false, false, 500*time.Millisecond)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got mapped address %v", response.Mapped)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("STUNBinding: %v", err)
+			}
+			if !response.Mapped.IP.Equal(mapped.IP) || response.Mapped.Port != mapped.Port {
+				t.Errorf("mapped address = %v, want %v", response.Mapped, mapped)
+			}
+			if response.From.Port != server.Port {
+				t.Errorf("response from %v, want %v", response.From, server)
+			}
+		})
+	}
+}
+
+func TestSTUNPublicIP(t *testing.T) {
+	server := stunTestResponder(t, func(request []byte, from *net.UDPAddr) []byte {
+		return buildSTUNTestResponse(request[8:20], stunAttrXORMappedAddress, from)
+	})
+
+	ip, err := stunPublicIP(context.Background(), server.String(), "IPv4")
+	if err != nil {
+		t.Fatalf("stunPublicIP: %v", err)
+	}
+	if ip != "127.0.0.1" {
+		t.Errorf("stunPublicIP = %q, want 127.0.0.1", ip)
+	}
+}
+
+func TestSTUNPublicIPCancelled(t *testing.T) {
+	silent := stunTestResponder(t, func([]byte, *net.UDPAddr) []byte { return nil })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := stunPublicIP(ctx, silent.String(), "IPv4"); err == nil {
+		t.Fatal("expected an error from a silent server")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("stunPublicIP returned after %v, want it to stop when the context is done", elapsed)
+	}
+}
+
+func TestParseSTUNResponse(t *testing.T) {
+	// RFC 5769 section 2.2: IPv4 response, mapped address 192.0.2.1:32853
+	vector := []byte{
+		0x01, 0x01, 0x00, 0x3c, 0x21, 0x12, 0xa4, 0x42,
+		0xb7, 0xe7, 0xa7, 0x01, 0xbc, 0x34, 0xd6, 0x86, 0xfa, 0x87, 0xdf, 0xae,
+		0x80, 0x22, 0x00, 0x0b, 0x74, 0x65, 0x73, 0x74, 0x20, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x20,
+		0x00, 0x20, 0x00, 0x08, 0x00, 0x01, 0xa1, 0x47, 0xe1, 0x12, 0xa6, 0x43,
+		0x00, 0x08, 0x00, 0x14, 0x2b, 0x91, 0xf5, 0x99, 0xfd, 0x9e, 0x90, 0xc3, 0x8c, 0x74,
+		0x89, 0xf9, 0x2a, 0xf9, 0xba, 0x53, 0xf0, 0x6b, 0xe7, 0xd7,
+		0x80, 0x28, 0x00, 0x04, 0xc0, 0x7d, 0x4c, 0x96,
+	}
+	transactionID := vector[8:20]
+
+	response, err := parseSTUNResponse(vector, transactionID)
+	if err != nil {
+		t.Fatalf("parseSTUNResponse: %v", err)
+	}
+	if got := response.Mapped.String(); got != "192.0.2.1:32853" {
+		t.Errorf("mapped address = %s, want 192.0.2.1:32853", got)
+	}
+
+	truncated := map[string][]byte{
+		"empty":            nil,
+		"short header":     vector[:12],
+		"header only":      vector[:stunHeaderSize],
+		"cut attribute":    vector[:40],
+		"cut mapped value": vector[:44],
+	}
+	for name, msg := range truncated {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseSTUNResponse(msg, transactionID); err == nil {
+				t.Error("expected an error for a truncated message")
+			}
+		})
+	}
+
+	// A consistent header whose XOR-MAPPED-ADDRESS value is cut short
+	cut := append([]byte{}, vector[:44]...)
+	binary.BigEndian.PutUint16(cut[2:], uint16(len(cut)-stunHeaderSize))
+	if _, err := parseSTUNResponse(cut, transactionID); err == nil {
+		t.Error("expected an error for a cut attribute value")
+	}
+}
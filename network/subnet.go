@@ -0,0 +1,318 @@
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+// maximum number of subnets SplitSubnet will list
+const maxSplitSubnets = 4096
+
+// SubnetInfo holds the calculated properties of an address block
+type SubnetInfo struct {
+	CIDR      string `json:"cidr"`    // as given, e.g. 192.168.1.10/24
+	Address   string `json:"address"` // address part of CIDR
+	Family    string `json:"family"`
+	PrefixLen int    `json:"prefix_len"`
+	Network   string `json:"network"`             // e.g. 192.168.1.0/24
+	Netmask   string `json:"netmask,omitempty"`   // IPv4 only
+	Wildcard  string `json:"wildcard,omitempty"`  // IPv4 only
+	Broadcast string `json:"broadcast,omitempty"` // IPv4 shorter than /31 only
+	FirstHost string `json:"first_host"`
+	LastHost  string `json:"last_host"`
+	Hosts     string `json:"hosts"`    // usable hosts; a string since IPv6 counts overflow uint64
+	Supernet  string `json:"supernet"` // enclosing block one bit shorter
+}
+
+// CalculateSubnet computes network, broadcast, host range and masks for a CIDR.
+// A bare address is treated as a host route (/32 or /128).
+func CalculateSubnet(cidr string) (*SubnetInfo, error) {
+	prefix, err := parsePrefix(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := prefix.Addr()
+	bits := prefix.Bits()
+	network := prefix.Masked()
+	first := network.Addr()
+	last := lastAddr(network)
+	size := new(big.Int).Lsh(big.NewInt(1), uint(addr.BitLen()-bits))
+
+	info := &SubnetInfo{
+		CIDR:      prefix.String(),
+		Address:   addr.String(),
+		Family:    ipFamily(addr.String()),
+		PrefixLen: bits,
+		Network:   network.String(),
+		FirstHost: first.String(),
+		LastHost:  last.String(),
+		Hosts:     size.String(),
+		Supernet:  network.String(),
+	}
+	if bits > 0 {
+		info.Supernet = netip.PrefixFrom(addr, bits-1).Masked().String()
+	}
+
+	if addr.Is4() {
+		mask := prefixMask(bits)
+		info.Netmask = netip.AddrFrom4(mask).String()
+		info.Wildcard = netip.AddrFrom4([4]byte{^mask[0], ^mask[1], ^mask[2], ^mask[3]}).String()
+		// Network and broadcast are not usable, except on /31 point-to-point links (RFC 3021) and /32
+		if bits < 31 {
+			info.Broadcast = last.String()
+			info.FirstHost = first.Next().String()
+			info.LastHost = last.Prev().String()
+			info.Hosts = new(big.Int).Sub(size, big.NewInt(2)).String()
+		}
+	} else if bits < 127 {
+		// The all-zeros address is the subnet-router anycast address (RFC 4291)
+		info.FirstHost = first.Next().String()
+		info.Hosts = new(big.Int).Sub(size, big.NewInt(1)).String()
+	}
+
+	return info, nil
+}
+
+// SplitSubnet divides a CIDR into subnets of the given prefix length
+func SplitSubnet(cidr string, newBits int) ([]string, error) {
+	prefix, err := parsePrefix(cidr)
+	if err != nil {
+		return nil, err
+	}
+	prefix = prefix.Masked()
+
+	if newBits < prefix.Bits() || newBits > prefix.Addr().BitLen() {
+		return nil, fmt.Errorf("cannot split %s into /%d subnets", prefix, newBits)
+	}
+	if newBits-prefix.Bits() > 12 {
+		return nil, fmt.Errorf("splitting %s into /%d would produce more than %d subnets", prefix, newBits, maxSplitSubnets)
+	}
+
+	count := 1 << uint(newBits-prefix.Bits())
+	step := new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-newBits))
+	start := addrToInt(prefix.Addr())
+
+	subnets := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		addr := intToAddr(start, prefix.Addr().Is4())
+		subnets = append(subnets, netip.PrefixFrom(addr, newBits).String())
+		start.Add(start, step)
+	}
+	return subnets, nil
+}
+
+// SummarizeCIDRs merges overlapping and adjacent blocks into the smallest exact set of CIDRs
+func SummarizeCIDRs(cidrs []string) ([]string, error) {
+	type span struct {
+		start, end *big.Int
+		is4        bool
+	}
+
+	var spans []span
+	for _, cidr := range cidrs {
+		prefix, err := parsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		prefix = prefix.Masked()
+		spans = append(spans, span{
+			start: addrToInt(prefix.Addr()),
+			end:   addrToInt(lastAddr(prefix)),
+			is4:   prefix.Addr().Is4(),
+		})
+	}
+
+	// IPv4 first, then by start address
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].is4 != spans[j].is4 {
+			return spans[i].is4
+		}
+		return spans[i].start.Cmp(spans[j].start) < 0
+	})
+
+	var merged []span
+	for _, s := range spans {
+		if n := len(merged); n > 0 && merged[n-1].is4 == s.is4 {
+			next := new(big.Int).Add(merged[n-1].end, big.NewInt(1))
+			if s.start.Cmp(next) <= 0 {
+				if s.end.Cmp(merged[n-1].end) > 0 {
+					merged[n-1].end = s.end
+				}
+				continue
+			}
+		}
+		merged = append(merged, s)
+	}
+
+	var result []string
+	for _, s := range merged {
+		result = append(result, rangeToCIDRs(s.start, s.end, s.is4)...)
+	}
+	return result, nil
+}
+
+// rangeToCIDRs covers the inclusive range start..end with the fewest aligned blocks
+func rangeToCIDRs(start, end *big.Int, is4 bool) []string {
+	bitLen := 128
+	if is4 {
+		bitLen = 32
+	}
+
+	var cidrs []string
+	current := new(big.Int).Set(start)
+	for current.Cmp(end) <= 0 {
+		// Largest block aligned at current that does not run past end
+		size := 0
+		for size < bitLen {
+			blockEnd := new(big.Int).Lsh(big.NewInt(1), uint(size+1))
+			blockEnd.Add(blockEnd, current).Sub(blockEnd, big.NewInt(1))
+			aligned := current.Bit(size) == 0
+			if !aligned || blockEnd.Cmp(end) > 0 {
+				break
+			}
+			size++
+		}
+		cidrs = append(cidrs, netip.PrefixFrom(intToAddr(current, is4), bitLen-size).String())
+		current.Add(current, new(big.Int).Lsh(big.NewInt(1), uint(size)))
+	}
+	return cidrs
+}
+
+// parsePrefix accepts "addr/len" or a bare address
+func parsePrefix(cidr string) (netip.Prefix, error) {
+	cidr = strings.TrimSpace(cidr)
+	if !strings.Contains(cidr, "/") {
+		addr, err := netip.ParseAddr(cidr)
+		if err != nil {
+			return netip.Prefix{}, utils.WrapError(err, fmt.Sprintf("invalid address: %s", cidr), utils.ErrorTypeValidation)
+		}
+		return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, utils.WrapError(err, fmt.Sprintf("invalid CIDR: %s", cidr), utils.ErrorTypeValidation)
+	}
+	return prefix, nil
+}
+
+// prefixMask returns the IPv4 netmask for a prefix length
+func prefixMask(bits int) [4]byte {
+	var mask [4]byte
+	value := uint32(0)
+	if bits > 0 {
+		value = ^uint32(0) << uint(32-bits)
+	}
+	mask[0], mask[1], mask[2], mask[3] = byte(value>>24), byte(value>>16), byte(value>>8), byte(value)
+	return mask
+}
+
+// lastAddr returns the highest address of a prefix
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	hostBits := uint(prefix.Addr().BitLen() - prefix.Bits())
+	last := addrToInt(prefix.Masked().Addr())
+	last.Add(last, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), hostBits), big.NewInt(1)))
+	return intToAddr(last, prefix.Addr().Is4())
+}
+
+// addrToInt converts an address to an integer
+func addrToInt(addr netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(addr.AsSlice())
+}
+
+// intToAddr converts an integer back to an address of the given family
+func intToAddr(value *big.Int, is4 bool) netip.Addr {
+	if is4 {
+		var b [4]byte
+		value.FillBytes(b[:])
+		return netip.AddrFrom4(b)
+	}
+	var b [16]byte
+	value.FillBytes(b[:])
+	return netip.AddrFrom16(b)
+}
+
+// ShowSubnetInformation displays the subnet of every local address
+func ShowSubnetInformation() error {
+	display.PrintInfo(utils.MsgGatheringInfo)
+
+	localIPs, err := GetLocalIPs()
+	if err != nil {
+		display.PrintError(utils.GetUserFriendlyMessage(err))
+		return err
+	}
+
+	var tableData [][]string
+	for _, ipInfo := range localIPs {
+		for _, addr := range ipInfo.Addresses {
+			subnet, err := CalculateSubnet(addr)
+			if err != nil {
+				continue
+			}
+			broadcast := subnet.Broadcast
+			if broadcast == "" {
+				broadcast = "-"
+			}
+			row := []string{
+				ipInfo.Interface,
+				subnet.CIDR,
+				subnet.Network,
+				broadcast,
+				fmt.Sprintf("%s - %s", subnet.FirstHost, subnet.LastHost),
+				subnet.Hosts,
+				subnet.Supernet,
+			}
+			tableData = append(tableData, row)
+		}
+	}
+
+	if len(tableData) == 0 {
+		display.PrintWarning(utils.MsgNoIPs)
+		return nil
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Interface Subnets"
+	tableConfig.Headers = []string{"Interface", "Address", "Network", "Broadcast", "Host Range", "Hosts", "Supernet"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 60
+
+	display.PrintTable(tableConfig)
+
+	return nil
+}
+
+// ShowSubnet displays the details of a single CIDR
+func ShowSubnet(cidr string) error {
+	subnet, err := CalculateSubnet(cidr)
+	if err != nil {
+		return err
+	}
+
+	details := map[string]string{
+		"Address":    subnet.Address,
+		"Network":    subnet.Network,
+		"Prefix":     fmt.Sprintf("/%d", subnet.PrefixLen),
+		"First Host": subnet.FirstHost,
+		"Last Host":  subnet.LastHost,
+		"Hosts":      subnet.Hosts,
+		"Supernet":   subnet.Supernet,
+	}
+	if subnet.Family == "IPv4" {
+		details["Netmask"] = subnet.Netmask
+		details["Wildcard"] = subnet.Wildcard
+	}
+	if subnet.Broadcast != "" {
+		details["Broadcast"] = subnet.Broadcast
+	}
+
+	display.PrintKeyValue(details, fmt.Sprintf("Subnet %s", subnet.CIDR))
+	return nil
+}
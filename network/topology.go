@@ -0,0 +1,378 @@
+package network
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+const (
+	procNetBonding = "/proc/net/bonding"
+	procVLANConfig = "/proc/net/vlan/config"
+)
+
+// STP port states from /sys/class/net/<port>/brport/state (linux/if_bridge.h)
+var stpPortStates = map[int64]string{
+	0: "disabled",
+	1: "listening",
+	2: "learning",
+	3: "forwarding",
+	4: "blocking",
+}
+
+// BondSlave is one member of a bond
+type BondSlave struct {
+	Name         string `json:"name"`
+	MIIStatus    string `json:"mii_status"`
+	Speed        string `json:"speed,omitempty"`
+	Duplex       string `json:"duplex,omitempty"`
+	LinkFailures int    `json:"link_failures"`
+	PermanentMAC string `json:"permanent_mac,omitempty"`
+}
+
+// BondInfo describes a bonding master
+type BondInfo struct {
+	Mode        string      `json:"mode"`
+	ActiveSlave string      `json:"active_slave,omitempty"`
+	MIIStatus   string      `json:"mii_status,omitempty"`
+	Slaves      []BondSlave `json:"slaves"`
+}
+
+// BridgeInfo describes a bridge
+type BridgeInfo struct {
+	STPEnabled bool   `json:"stp_enabled"`
+	BridgeID   string `json:"bridge_id,omitempty"`
+	RootID     string `json:"root_id,omitempty"`
+}
+
+// TopologyNode is an interface with the devices stacked on it
+type TopologyNode struct {
+	Name      string          `json:"name"`
+	Kind      string          `json:"kind"`
+	OperState string          `json:"oper_state"`
+	Parent    string          `json:"parent,omitempty"`     // lower device of a vlan or macvlan
+	VLANID    int             `json:"vlan_id,omitempty"`    // for vlan devices
+	PortState string          `json:"port_state,omitempty"` // STP state when this is a bridge port
+	Bond      *BondInfo       `json:"bond,omitempty"`
+	Bridge    *BridgeInfo     `json:"bridge,omitempty"`
+	Children  []*TopologyNode `json:"children,omitempty"`
+}
+
+// GetInterfaceTopology returns the interfaces as a forest: bond slaves and bridge ports
+// under their master, VLANs and macvlans under their parent when they have no master
+func GetInterfaceTopology() ([]*TopologyNode, error) {
+	if !utils.IsLinux() {
+		return nil, fmt.Errorf("interface topology is only available on Linux")
+	}
+
+	interfaces, err := GetNetworkInterfacesDetailed()
+	if err != nil {
+		return nil, err
+	}
+	links := readIPLinks()
+	vlanConfig := readVLANConfig()
+
+	nodes := make(map[string]*TopologyNode)
+	var order []string
+	for _, iface := range interfaces {
+		if iface.Link == nil {
+			continue
+		}
+		node := &TopologyNode{
+			Name:      iface.Name,
+			Kind:      iface.Link.Kind,
+			OperState: iface.Link.OperState,
+		}
+		if iface.Link.Kind == LinkKindVLAN || iface.Link.Kind == LinkKindMacvlan {
+			node.Parent = iface.Link.Parent
+		}
+
+		switch iface.Link.Kind {
+		case LinkKindVLAN:
+			node.VLANID = vlanID(links[iface.Name])
+			if vlan, ok := vlanConfig[iface.Name]; ok {
+				if node.VLANID == 0 {
+					node.VLANID = vlan.id
+				}
+				if node.Parent == "" {
+					node.Parent = vlan.parent
+				}
+			}
+		case LinkKindBond:
+			node.Bond = readBondInfo(iface.Name)
+		case LinkKindBridge:
+			node.Bridge = readBridgeInfo(iface.Name)
+		}
+
+		if state, ok := sysfsInt(iface.Name, "brport/state"); ok {
+			node.PortState = stpPortStates[state]
+		}
+
+		nodes[iface.Name] = node
+		order = append(order, iface.Name)
+	}
+
+	var roots []*TopologyNode
+	for _, name := range order {
+		node := nodes[name]
+		upper := ""
+		if master := interfaceMaster(interfaces, name); master != "" {
+			upper = master
+		} else if node.Parent != "" {
+			upper = node.Parent
+		}
+		if parent, ok := nodes[upper]; ok && upper != name {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sortTopology(roots)
+	return roots, nil
+}
+
+// interfaceMaster returns the master device of name
+func interfaceMaster(interfaces []InterfaceInfo, name string) string {
+	for _, iface := range interfaces {
+		if iface.Name == name && iface.Link != nil {
+			return iface.Link.Master
+		}
+	}
+	return ""
+}
+
+// sortTopology orders every level by name
+func sortTopology(nodes []*TopologyNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	for _, node := range nodes {
+		sortTopology(node.Children)
+	}
+}
+
+// vlanID reads the 802.1Q ID from the netlink link info
+func vlanID(link ipLink) int {
+	var data struct {
+		ID int `json:"id"`
+	}
+	json.Unmarshal(link.LinkInfo.InfoData, &data)
+	return data.ID
+}
+
+type vlanEntry struct {
+	id     int
+	parent string
+}
+
+// readVLANConfig parses /proc/net/vlan/config ("bond0.100 | 100 | bond0")
+func readVLANConfig() map[string]vlanEntry {
+	entries := make(map[string]vlanEntry)
+	file, err := os.Open(procVLANConfig)
+	if err != nil {
+		return entries
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "|")
+		if len(fields) != 3 {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+		if err != nil {
+			continue // header lines
+		}
+		entries[strings.TrimSpace(fields[0])] = vlanEntry{id: id, parent: strings.TrimSpace(fields[2])}
+	}
+	return entries
+}
+
+// readBondInfo parses /proc/net/bonding/<bond>, falling back to the bonding sysfs attributes
+func readBondInfo(name string) *BondInfo {
+	bond := &BondInfo{}
+
+	file, err := os.Open(filepath.Join(procNetBonding, name))
+	if err != nil {
+		// e.g. "active-backup 1"
+		if mode := strings.Fields(readSysfs(name, "bonding/mode")); len(mode) > 0 {
+			bond.Mode = mode[0]
+		}
+		bond.ActiveSlave = readSysfs(name, "bonding/active_slave")
+		for _, slave := range strings.Fields(readSysfs(name, "bonding/slaves")) {
+			bond.Slaves = append(bond.Slaves, BondSlave{
+				Name:      slave,
+				MIIStatus: readSysfs(slave, "bonding_slave/mii_status"),
+			})
+		}
+		return bond
+	}
+	defer file.Close()
+
+	var slave *BondSlave
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Bonding Mode":
+			bond.Mode = value
+		case "Currently Active Slave":
+			bond.ActiveSlave = value
+		case "Slave Interface":
+			bond.Slaves = append(bond.Slaves, BondSlave{Name: value})
+			slave = &bond.Slaves[len(bond.Slaves)-1]
+		case "MII Status":
+			// The first MII Status belongs to the bond, later ones to the slave above them
+			if slave == nil {
+				bond.MIIStatus = value
+			} else {
+				slave.MIIStatus = value
+			}
+		case "Speed":
+			if slave != nil {
+				slave.Speed = value
+			}
+		case "Duplex":
+			if slave != nil {
+				slave.Duplex = value
+			}
+		case "Link Failure Count":
+			if slave != nil {
+				slave.LinkFailures, _ = strconv.Atoi(value)
+			}
+		case "Permanent HW addr":
+			if slave != nil {
+				slave.PermanentMAC = value
+			}
+		}
+	}
+	return bond
+}
+
+// readBridgeInfo reads the STP settings of a bridge
+func readBridgeInfo(name string) *BridgeInfo {
+	stp, _ := sysfsInt(name, "bridge/stp_state")
+	return &BridgeInfo{
+		STPEnabled: stp != 0,
+		BridgeID:   readSysfs(name, "bridge/bridge_id"),
+		RootID:     readSysfs(name, "bridge/root_id"),
+	}
+}
+
+// ShowInterfaceTopology prints the bridge, bond and VLAN hierarchy as a tree
+func ShowInterfaceTopology() error {
+	display.PrintInfo(utils.MsgGatheringInfo)
+
+	roots, err := GetInterfaceTopology()
+	if err != nil {
+		display.PrintError(utils.GetUserFriendlyMessage(err))
+		return err
+	}
+	if len(roots) == 0 {
+		display.PrintWarning(utils.MsgNoInterfaces)
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println(display.Info("Interface Topology"))
+	fmt.Println(strings.Repeat("─", 57))
+	for _, root := range roots {
+		printTopologyNode(root, "", "", nil)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// printTopologyNode prints node and its children; bond is the bond node is enslaved to, if any
+func printTopologyNode(node *TopologyNode, prefix, branch string, bond *BondInfo) {
+	state := node.OperState
+	switch state {
+	case "up":
+		state = display.Success(state)
+	case "down", "lowerlayerdown":
+		state = display.Error(state)
+	default:
+		state = display.Muted(state)
+	}
+
+	details := []string{display.Secondary(node.Kind), state}
+	switch {
+	case node.VLANID != 0:
+		details = append(details, fmt.Sprintf("VLAN %d on %s", node.VLANID, valueOrDash(node.Parent)))
+	case node.Parent != "":
+		details = append(details, "on "+node.Parent)
+	}
+	if node.Bond != nil {
+		details = append(details, "mode "+valueOrDash(node.Bond.Mode))
+		if node.Bond.ActiveSlave != "" && node.Bond.ActiveSlave != "None" {
+			details = append(details, "active "+node.Bond.ActiveSlave)
+		}
+	}
+	if node.Bridge != nil {
+		if node.Bridge.STPEnabled {
+			details = append(details, "STP on")
+		} else {
+			details = append(details, "STP off")
+		}
+	}
+	if node.PortState != "" {
+		portState := node.PortState
+		if portState == "forwarding" {
+			portState = display.Success(portState)
+		} else {
+			portState = display.Warning(portState)
+		}
+		details = append(details, "port "+portState)
+	}
+	if bond != nil {
+		for _, slave := range bond.Slaves {
+			if slave.Name != node.Name {
+				continue
+			}
+			link := display.Success("link " + slave.MIIStatus)
+			if slave.MIIStatus != "up" {
+				link = display.Error("link " + slave.MIIStatus)
+			}
+			details = append(details, link)
+			if slave.Name == bond.ActiveSlave {
+				details = append(details, display.Success("active"))
+			}
+			if slave.LinkFailures > 0 {
+				details = append(details, display.Warning(fmt.Sprintf("%d link failures", slave.LinkFailures)))
+			}
+		}
+	}
+
+	fmt.Printf("%s%s%s  %s\n", prefix, branch, display.IP(node.Name), strings.Join(details, ", "))
+
+	childPrefix := prefix
+	switch branch {
+	case "├── ":
+		childPrefix += "│   "
+	case "└── ":
+		childPrefix += "    "
+	}
+	for i, child := range node.Children {
+		childBranch := "├── "
+		if i == len(node.Children)-1 {
+			childBranch = "└── "
+		}
+		printTopologyNode(child, childPrefix, childBranch, node.Bond)
+	}
+}
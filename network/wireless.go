@@ -0,0 +1,602 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"netinfo/display"
+	"netinfo/utils"
+)
+
+const procNetWireless = "/proc/net/wireless"
+
+// cfg80211 reports link quality on a 0-70 scale in /proc/net/wireless
+const wirelessQualityMax = 70
+
+// WirelessInfo holds the association and radio state of a wireless interface
+type WirelessInfo struct {
+	Interface    string  `json:"interface"`
+	Connected    bool    `json:"connected"`
+	Mode         string  `json:"mode,omitempty"` // managed, AP, monitor...
+	SSID         string  `json:"ssid,omitempty"`
+	BSSID        string  `json:"bssid,omitempty"`
+	FrequencyMHz float64 `json:"frequency_mhz,omitempty"`
+	Channel      int     `json:"channel,omitempty"`
+	Band         string  `json:"band,omitempty"` // 2.4 GHz, 5 GHz or 6 GHz
+	ChannelWidth string  `json:"channel_width,omitempty"`
+	SignalDBm    *int    `json:"signal_dbm,omitempty"`
+	NoiseDBm     *int    `json:"noise_dbm,omitempty"`
+	RxBitrate    string  `json:"rx_bitrate,omitempty"`
+	TxBitrate    string  `json:"tx_bitrate,omitempty"`
+	LinkQuality  int     `json:"link_quality"` // out of QualityMax
+	QualityMax   int     `json:"link_quality_max"`
+	Source       string  `json:"source"` // nl80211 (iw), proc or both
+}
+
+// SNR returns the signal to noise ratio in dB, or false when either level is unknown
+func (w *WirelessInfo) SNR() (int, bool) {
+	if w.SignalDBm == nil || w.NoiseDBm == nil {
+		return 0, false
+	}
+	return *w.SignalDBm - *w.NoiseDBm, true
+}
+
+// isWireless reports whether the interface is an 802.11 device
+func isWireless(name string) bool {
+	dir := filepath.Join(sysClassNet, name)
+	if exists(filepath.Join(dir, "wireless")) || exists(filepath.Join(dir, "phy80211")) {
+		return true
+	}
+	return strings.Contains(readSysfs(name, "uevent"), "DEVTYPE=wlan")
+}
+
+// WirelessInterfaces returns the names of the wireless interfaces, sorted
+func WirelessInterfaces() []string {
+	var names []string
+	entries, err := os.ReadDir(sysClassNet)
+	if err != nil {
+		return names
+	}
+	for _, entry := range entries {
+		if isWireless(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetWirelessInfo reads the state of one wireless interface from nl80211 through iw,
+// with link quality and levels from /proc/net/wireless when iw is missing
+func GetWirelessInfo(name string) (*WirelessInfo, error) {
+	if !utils.IsLinux() {
+		return nil, fmt.Errorf("wireless details are only available on Linux")
+	}
+	if !isWireless(name) {
+		return nil, fmt.Errorf("%s is not a wireless interface", name)
+	}
+
+	info := &WirelessInfo{Interface: name, QualityMax: wirelessQualityMax}
+	var sources []string
+
+	if _, err := exec.LookPath("iw"); err == nil {
+		readIwInfo(info)
+		if readIwLink(info) {
+			sources = append(sources, "nl80211")
+		}
+		readIwSurvey(info)
+	}
+
+	if proc, ok := readProcWireless()[name]; ok {
+		sources = append(sources, "proc")
+		info.LinkQuality = proc.quality
+		if info.SignalDBm == nil && proc.level != nil {
+			info.SignalDBm = proc.level
+		}
+		if info.NoiseDBm == nil && proc.noise != nil {
+			info.NoiseDBm = proc.noise
+		}
+		// Associated stations have a non-zero quality even without iw
+		if len(sources) == 1 && proc.quality > 0 {
+			info.Connected = true
+		}
+	} else if info.SignalDBm != nil {
+		info.LinkQuality = qualityFromSignal(*info.SignalDBm)
+	}
+
+	if info.FrequencyMHz > 0 && info.Channel == 0 {
+		info.Channel, info.Band = frequencyChannel(info.FrequencyMHz)
+	}
+	info.Source = strings.Join(sources, "+")
+	if info.Source == "" {
+		info.Source = "none"
+	}
+	return info, nil
+}
+
+// GetWirelessDetails returns the state of the named wireless interfaces, or of all of them
+func GetWirelessDetails(names []string) ([]*WirelessInfo, error) {
+	if len(names) == 0 {
+		names = WirelessInterfaces()
+	}
+	details := []*WirelessInfo{}
+	for _, name := range names {
+		info, err := GetWirelessInfo(name)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, info)
+	}
+	return details, nil
+}
+
+// iwOutput runs iw with args and returns its output, or "" on failure
+func iwOutput(args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), utils.LinuxCommandTimeout)
+	defer cancel()
+
+	output, err := utils.CommandWithTimeout(ctx, 5*time.Second, "iw", args...)
+	if err != nil {
+		return ""
+	}
+	return string(output)
+}
+
+// readIwLink parses "iw dev <name> link" and reports whether iw answered
+func readIwLink(info *WirelessInfo) bool {
+	output := iwOutput("dev", info.Interface, "link")
+	if output == "" {
+		return false
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// "Connected to 11:22:33:44:55:66 (on wlan0)"
+		if rest, ok := strings.CutPrefix(line, "Connected to "); ok {
+			info.Connected = true
+			if fields := strings.Fields(rest); len(fields) > 0 {
+				info.BSSID = fields[0]
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "SSID":
+			info.SSID = value
+		case "freq":
+			info.FrequencyMHz, _ = strconv.ParseFloat(value, 64)
+		case "signal":
+			// "-52 dBm", or "-52 [-54, -55] dBm" with per-chain levels
+			if fields := strings.Fields(value); len(fields) > 0 {
+				if signal, err := strconv.Atoi(fields[0]); err == nil {
+					info.SignalDBm = &signal
+				}
+			}
+		case "rx bitrate":
+			info.RxBitrate = bitrateValue(value)
+		case "tx bitrate":
+			info.TxBitrate = bitrateValue(value)
+		}
+	}
+	return true
+}
+
+// bitrateValue keeps the rate of an iw bitrate line ("585.0 MBit/s 80MHz HE-MCS 6 ...")
+func bitrateValue(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return value
+	}
+	return fields[0] + " " + fields[1]
+}
+
+// readIwInfo parses "iw dev <name> info" for the mode, channel and width
+func readIwInfo(info *WirelessInfo) {
+	scanner := bufio.NewScanner(strings.NewReader(iwOutput("dev", info.Interface, "info")))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "type":
+			info.Mode = fields[1]
+		case "ssid":
+			// An access point has no link to report but still has an SSID
+			info.SSID = strings.TrimPrefix(line, "ssid ")
+		case "channel":
+			// "channel 36 (5180 MHz), width: 80 MHz, center1: 5210 MHz"
+			info.Channel, _ = strconv.Atoi(fields[1])
+			if len(fields) > 2 {
+				info.FrequencyMHz, _ = strconv.ParseFloat(strings.TrimPrefix(fields[2], "("), 64)
+				_, info.Band = frequencyChannel(info.FrequencyMHz)
+			}
+			if _, width, ok := strings.Cut(line, "width: "); ok {
+				info.ChannelWidth, _, _ = strings.Cut(width, ",")
+			}
+		}
+	}
+}
+
+// readIwSurvey takes the noise floor of the channel in use from "iw dev <name> survey dump"
+func readIwSurvey(info *WirelessInfo) {
+	inUse := false
+	scanner := bufio.NewScanner(strings.NewReader(iwOutput("dev", info.Interface, "survey", "dump")))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "frequency":
+			inUse = strings.Contains(value, "[in use]")
+		case "noise":
+			if !inUse {
+				continue
+			}
+			if fields := strings.Fields(value); len(fields) > 0 {
+				if noise, err := strconv.Atoi(fields[0]); err == nil {
+					info.NoiseDBm = &noise
+				}
+			}
+		}
+	}
+}
+
+type procWireless struct {
+	quality int
+	level   *int
+	noise   *int
+}
+
+// readProcWireless parses /proc/net/wireless:
+// "wlan0: 0000   54.  -56.  -256        0      0      0      0      0        0"
+func readProcWireless() map[string]procWireless {
+	entries := make(map[string]procWireless)
+	file, err := os.Open(procNetWireless)
+	if err != nil {
+		return entries
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) < 4 {
+			continue // header lines
+		}
+		level := func(field string) *int {
+			value, err := strconv.ParseFloat(strings.TrimSuffix(field, "."), 64)
+			// -256 means the driver does not report the level
+			if err != nil || value <= -256 || value == 0 {
+				return nil
+			}
+			dbm := int(value)
+			return &dbm
+		}
+		quality, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "."), 64)
+		if err != nil {
+			continue
+		}
+		entries[strings.TrimSpace(name)] = procWireless{
+			quality: int(quality),
+			level:   level(fields[2]),
+			noise:   level(fields[3]),
+		}
+	}
+	return entries
+}
+
+// qualityFromSignal maps a signal level to the 0-70 scale the way cfg80211 does
+func qualityFromSignal(dbm int) int {
+	quality := dbm + 110
+	if quality < 0 {
+		return 0
+	}
+	if quality > wirelessQualityMax {
+		return wirelessQualityMax
+	}
+	return quality
+}
+
+// frequencyChannel converts a centre frequency to its 802.11 channel number and band
+func frequencyChannel(mhz float64) (int, string) {
+	freq := int(mhz)
+	switch {
+	case freq == 2484:
+		return 14, "2.4 GHz"
+	case freq >= 2412 && freq <= 2472:
+		return (freq - 2407) / 5, "2.4 GHz"
+	case freq == 5935:
+		return 2, "6 GHz"
+	case freq >= 5955 && freq <= 7115:
+		return (freq - 5950) / 5, "6 GHz"
+	case freq >= 5150 && freq <= 5925:
+		return (freq - 5000) / 5, "5 GHz"
+	}
+	return 0, ""
+}
+
+// signalRating describes a signal level and colours it
+func signalRating(dbm int) string {
+	text := fmt.Sprintf("%d dBm", dbm)
+	switch {
+	case dbm >= -50:
+		return display.Success(text + " (excellent)")
+	case dbm >= -60:
+		return display.Success(text + " (good)")
+	case dbm >= -70:
+		return display.Warning(text + " (fair)")
+	default:
+		return display.Error(text + " (weak)")
+	}
+}
+
+// attachWireless fills in the wireless state of each 802.11 interface
+func attachWireless(interfaces []InterfaceInfo) {
+	if !utils.IsLinux() {
+		return
+	}
+	for i := range interfaces {
+		if !isWireless(interfaces[i].Name) {
+			continue
+		}
+		if info, err := GetWirelessInfo(interfaces[i].Name); err == nil {
+			interfaces[i].Wireless = info
+		}
+	}
+}
+
+// printWirelessInfo draws the wireless table, skipping it on hosts without Wi-Fi
+func printWirelessInfo(details []*WirelessInfo) {
+	var tableData [][]string
+	for _, info := range details {
+		if info == nil {
+			continue
+		}
+
+		network := display.Muted("not connected")
+		if info.Connected || info.SSID != "" {
+			network = valueOrDash(info.SSID)
+			if info.BSSID != "" {
+				network += " (" + info.BSSID + ")"
+			}
+		}
+		channel := "-"
+		if info.Channel > 0 {
+			channel = fmt.Sprintf("%d, %s", info.Channel, info.Band)
+			if info.ChannelWidth != "" {
+				channel += ", " + info.ChannelWidth
+			}
+		} else if info.FrequencyMHz > 0 {
+			channel = fmt.Sprintf("%.0f MHz", info.FrequencyMHz)
+		}
+		signal := "-"
+		if info.SignalDBm != nil {
+			signal = signalRating(*info.SignalDBm)
+		}
+		noise := "-"
+		if info.NoiseDBm != nil {
+			noise = fmt.Sprintf("%d dBm", *info.NoiseDBm)
+			if snr, ok := info.SNR(); ok {
+				noise += fmt.Sprintf(" (SNR %d dB)", snr)
+			}
+		}
+		bitrate := "-"
+		if info.RxBitrate != "" || info.TxBitrate != "" {
+			bitrate = fmt.Sprintf("%s / %s", valueOrDash(info.RxBitrate), valueOrDash(info.TxBitrate))
+		}
+
+		row := []string{
+			info.Interface,
+			valueOrDash(info.Mode),
+			network,
+			channel,
+			signal,
+			noise,
+			bitrate,
+			fmt.Sprintf("%d/%d", info.LinkQuality, info.QualityMax),
+		}
+		tableData = append(tableData, row)
+	}
+
+	if len(tableData) == 0 {
+		return
+	}
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = "Wireless"
+	tableConfig.Headers = []string{"Interface", "Mode", "Network", "Channel", "Signal", "Noise", "Bitrate RX / TX", "Quality"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 40
+
+	display.PrintTable(tableConfig)
+
+	for _, info := range details {
+		if info != nil && info.Source == "none" {
+			display.PrintWarning(fmt.Sprintf("%s: install iw for SSID, channel and bitrate details", info.Interface))
+		}
+	}
+}
+
+// ShowWirelessDetails prints the named wireless interfaces, or all of them, with a note when there are none
+func ShowWirelessDetails(names []string) error {
+	details, err := GetWirelessDetails(names)
+	if err != nil {
+		display.PrintError(utils.GetUserFriendlyMessage(err))
+		return err
+	}
+	if len(details) == 0 {
+		display.PrintInfo("No wireless interfaces found")
+		return nil
+	}
+	printWirelessInfo(details)
+	return nil
+}
+
+// SignalSample is one reading of the signal watch
+type SignalSample struct {
+	Time time.Time `json:"time"`
+	*WirelessInfo
+}
+
+// signalTracker keeps the range and recent history of one interface's signal
+type signalTracker struct {
+	samples  int
+	sum      int
+	min, max int
+	history  []float64 // dBm above -100, newest last
+}
+
+// add records one signal reading
+func (t *signalTracker) add(dbm int) {
+	if t.samples == 0 || dbm < t.min {
+		t.min = dbm
+	}
+	if t.samples == 0 || dbm > t.max {
+		t.max = dbm
+	}
+	t.samples++
+	t.sum += dbm
+
+	level := float64(dbm + 100)
+	if level < 0 {
+		level = 0
+	}
+	t.history = append(t.history, level)
+	if len(t.history) > sparklineWidth {
+		t.history = t.history[len(t.history)-sparklineWidth:]
+	}
+}
+
+// WatchWirelessSignal reads the signal of the named wireless interfaces, or all of
+// them, every interval until interrupted. With stream set, every reading is written
+// to stdout as one JSON object per line instead of a table.
+func WatchWirelessSignal(interval time.Duration, names []string, stream bool) error {
+	if !utils.IsLinux() {
+		return fmt.Errorf("wireless details are only available on Linux")
+	}
+	if len(names) == 0 {
+		names = WirelessInterfaces()
+		if len(names) == 0 {
+			return fmt.Errorf("no wireless interfaces found")
+		}
+	}
+	for _, name := range names {
+		if !isWireless(name) {
+			return fmt.Errorf("%s is not a wireless interface", name)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	trackers := make(map[string]*signalTracker)
+	encoder := json.NewEncoder(os.Stdout)
+
+	if !stream {
+		display.PrintInfo(fmt.Sprintf("Reading signal every %s (Ctrl-C to stop)...", interval))
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var samples []SignalSample
+		for _, name := range names {
+			info, err := GetWirelessInfo(name)
+			if err != nil {
+				return err
+			}
+			if trackers[name] == nil {
+				trackers[name] = &signalTracker{}
+			}
+			if info.SignalDBm != nil {
+				trackers[name].add(*info.SignalDBm)
+			}
+			samples = append(samples, SignalSample{Time: time.Now(), WirelessInfo: info})
+		}
+
+		if stream {
+			for _, sample := range samples {
+				if err := encoder.Encode(sample); err != nil {
+					return err
+				}
+			}
+		} else {
+			printSignalWatch(samples, trackers, interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// printSignalWatch redraws the signal watch table in place
+func printSignalWatch(samples []SignalSample, trackers map[string]*signalTracker, interval time.Duration) {
+	var tableData [][]string
+	for _, sample := range samples {
+		tracker := trackers[sample.Interface]
+		signal, rangeText, average := display.Muted("no signal"), "-", "-"
+		if sample.SignalDBm != nil {
+			signal = signalRating(*sample.SignalDBm)
+		}
+		if tracker.samples > 0 {
+			rangeText = fmt.Sprintf("%d / %d dBm", tracker.min, tracker.max)
+			average = fmt.Sprintf("%.1f dBm", float64(tracker.sum)/float64(tracker.samples))
+		}
+		noise := "-"
+		if snr, ok := sample.SNR(); ok {
+			noise = fmt.Sprintf("%d dB", snr)
+		}
+
+		row := []string{
+			sample.Interface,
+			valueOrDash(sample.SSID),
+			signal,
+			noise,
+			fmt.Sprintf("%d/%d", sample.LinkQuality, sample.QualityMax),
+			valueOrDash(sample.TxBitrate),
+			rangeText,
+			average,
+			sparkline(tracker.history),
+		}
+		tableData = append(tableData, row)
+	}
+
+	display.ClearScreen()
+
+	tableConfig := display.NewTableConfig()
+	tableConfig.Title = fmt.Sprintf("Wireless Signal - every %s, %s (Ctrl-C to stop)", interval, time.Now().Format("15:04:05"))
+	tableConfig.Headers = []string{"Interface", "SSID", "Signal", "SNR", "Quality", "TX Bitrate", "Min / Max", "Average", "History"}
+	tableConfig.Data = tableData
+	tableConfig.MaxWidth = 40
+
+	display.PrintTable(tableConfig)
+}
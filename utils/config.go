@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ConfigEnvVar overrides the location of the configuration file
+const ConfigEnvVar = "NETINFO_CONFIG"
+
+// Config holds user-tunable settings loaded from the configuration file
+type Config struct {
+	// DNS diagnostics
+	ReferenceResolver string   `json:"reference_resolver"`
+	DNSCheckNames     []string `json:"dns_check_names"`
+
+	// Extra resolvers queried by the propagation checker
+	PropagationResolvers []string `json:"propagation_resolvers"`
+
+	// Public IP discovery; empty endpoint lists use the built-in ones
+	PublicIPMethods    []string           `json:"public_ip_methods"` // http, dns, stun
+	PublicIPEndpoints  []string           `json:"public_ip_endpoints"`
+	PublicIPDNSQueries []PublicIPDNSQuery `json:"public_ip_dns_queries"`
+	STUNServers        []string           `json:"stun_servers"`
+
+	// STUN servers with RFC 5780 support used for NAT behaviour tests
+	NATServers []string `json:"nat_servers"`
+
+	// Offline GeoIP/ASN databases in MaxMind .mmdb format; empty paths try the usual install locations
+	GeoIPCityDB      string `json:"geoip_city_db"`
+	GeoIPASNDB       string `json:"geoip_asn_db"`
+	GeoIPConnections bool   `json:"geoip_connections"` // also locate connection remotes
+
+	// host:port used by the dual-stack readiness test and Happy Eyeballs race
+	DualStackTarget string `json:"dual_stack_target"`
+
+	// Public IP history and change alerts; an empty history path uses the config directory
+	PublicIPHistoryFile   string `json:"public_ip_history_file"`
+	PublicIPWatchInterval string `json:"public_ip_watch_interval"` // Go duration, e.g. "5m"
+	PublicIPHookCommand   string `json:"public_ip_hook_command"`   // run through the shell on change
+	PublicIPWebhook       string `json:"public_ip_webhook"`        // receives a JSON POST on change
+}
+
+// PublicIPDNSQuery is a DNS "what is my IP" query sent directly to a name server
+type PublicIPDNSQuery struct {
+	Server string `json:"server"` // host or host:port
+	Name   string `json:"name"`
+	Type   string `json:"type"` // "A" (AAAA over IPv6) or "TXT"
+}
+
+var (
+	config     *Config
+	configOnce sync.Once
+)
+
+// DefaultConfig returns the built-in configuration
+func DefaultConfig() *Config {
+	return &Config{
+		ReferenceResolver: "1.1.1.1",
+		DNSCheckNames: []string{
+			"example.com",
+			"wikipedia.org",
+			"github.com",
+		},
+		PropagationResolvers: []string{
+			"1.1.1.1",
+			"8.8.8.8",
+			"9.9.9.9",
+			"208.67.222.222",
+		},
+		PublicIPMethods: []string{"http", "dns", "stun"},
+		PublicIPDNSQueries: []PublicIPDNSQuery{
+			{Server: "resolver1.opendns.com", Name: "myip.opendns.com", Type: "A"},
+			{Server: "ns1.google.com", Name: "o-o.myaddr.l.google.com", Type: "TXT"},
+		},
+		STUNServers: []string{
+			"stun.l.google.com:19302",
+			"stun.cloudflare.com:3478",
+		},
+		NATServers: []string{
+			"stun.stunprotocol.org:3478",
+		},
+		DualStackTarget:       "www.google.com:443",
+		PublicIPWatchInterval: "5m",
+	}
+}
+
+// ConfigPath returns the path of the configuration file
+func ConfigPath() string {
+	if path := os.Getenv(ConfigEnvVar); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "netinfo.json"
+	}
+	return filepath.Join(dir, "netinfo", "config.json")
+}
+
+// PublicIPHistoryPath returns the path of the public IP history file
+func (c *Config) PublicIPHistoryPath() string {
+	if c.PublicIPHistoryFile != "" {
+		return c.PublicIPHistoryFile
+	}
+	return filepath.Join(filepath.Dir(ConfigPath()), "public_ip_history.jsonl")
+}
+
+// GetConfig returns the configuration, loading it on first use.
+// A missing or unreadable file leaves the defaults in place.
+func GetConfig() *Config {
+	configOnce.Do(func() {
+		config = DefaultConfig()
+
+		content, err := os.ReadFile(ConfigPath())
+		if err != nil {
+			return
+		}
+		// Fields absent from the file keep their defaults
+		if err := json.Unmarshal(content, config); err != nil {
+			config = DefaultConfig()
+		}
+	})
+	return config
+}
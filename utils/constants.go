@@ -22,6 +22,17 @@ const (
 	// HTTP operation timeouts
 	HTTPTimeout        = 5 * time.Second
 	PublicIPTimout     = 10 * time.Second
+	WebhookTimeout     = 10 * time.Second
+	STUNTimeout        = 3 * time.Second
+	
+	// Reverse DNS enrichment
+	PTRLookupDeadline  = 2 * time.Second
+	PTRDefaultTTL      = 10 * time.Minute
+	PTRMinTTL          = 1 * time.Minute
+	PTRNegativeTTL     = 5 * time.Minute
+	
+	// Interface counters are sampled twice this far apart to spot growing errors
+	CounterSampleInterval = 1 * time.Second
 	
 	// Retry configuration
 	MaxRetries         = 3
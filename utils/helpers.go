@@ -27,7 +27,18 @@ func FormatDuration(d time.Duration) string {
 }
 
 // FormatBytes formats bytes into a human-readable string.
-// (removed) FormatBytes: unused helper
+func FormatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
 
 // CleanString removes extra whitespace and newlines from a string.
 // (removed) CleanString: unused helper
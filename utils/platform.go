@@ -2,17 +2,24 @@ package utils
 
 import (
 	"context"
+	"os"
 	"os/exec"
 	"runtime"
 	"time"
 )
 
+// NetnsEnvVar names the network namespace a re-executed netinfo runs in
+const NetnsEnvVar = "NETINFO_NETNS"
+
 // IsWindows returns true when running on Windows.
 func IsWindows() bool { return runtime.GOOS == "windows" }
 
 // IsLinux returns true when running on Linux.
 func IsLinux() bool { return runtime.GOOS == "linux" }
 
+// CurrentNetns returns the namespace given with --netns, or "" when running where started.
+func CurrentNetns() string { return os.Getenv(NetnsEnvVar) }
+
 // CommandWithTimeout executes a command with timeout and returns the output.
 func CommandWithTimeout(ctx context.Context, timeout time.Duration, name string, args ...string) ([]byte, error) {
 	// Create a context with timeout